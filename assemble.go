@@ -1,7 +1,9 @@
 package minifac
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"sort"
 
 	"github.com/mazzegi/minifac/grid"
@@ -9,13 +11,26 @@ import (
 )
 
 var _ ProducerConsumer = &Assembler{}
+var _ MultiProducer = &Assembler{}
+var _ StatusReporter = &Assembler{}
+var _ PowerConsumer = &Assembler{}
 
 func NewAssembler(name string, receipt Receipt, inCapa int, outCapa int) *Assembler {
+	return NewSizedAssembler(name, grid.S(1, 1), receipt, inCapa, outCapa)
+}
+
+// NewSizedAssembler creates an assembler occupying more than one tile.
+func NewSizedAssembler(name string, size grid.Size, receipt Receipt, inCapa int, outCapa int) *Assembler {
 	a := &Assembler{
-		name:     name,
-		receipt:  receipt,
-		inStocks: make(map[Resource]*Stock),
-		outStock: NewStock(outCapa),
+		name:         name,
+		size:         size,
+		receipt:      receipt,
+		inCapa:       inCapa,
+		inStocks:     make(map[Resource]*Stock),
+		outStock:     NewStock(outCapa),
+		leftovers:    NewStock(0),
+		speed:        1,
+		satisfaction: 1,
 	}
 	for inRes := range receipt.Input {
 		a.inStocks[inRes] = NewStock(inCapa)
@@ -24,17 +39,67 @@ func NewAssembler(name string, receipt Receipt, inCapa int, outCapa int) *Assemb
 }
 
 type Assembler struct {
-	name         string
-	receipt      Receipt
-	inStocks     map[Resource]*Stock
-	outStock     *Stock
-	lastProdTick int
-	currTick     int
-	producing    bool
+	name       string
+	size       grid.Size
+	receipt    Receipt
+	inCapa     int
+	inStocks   map[Resource]*Stock
+	outStock   *Stock
+	leftovers  *Stock  // items of a previous receipt, which are produced before any output
+	speed      float64 // crafting speed multiplier
+	progress   float64 // ticks of work done on the current production
+	producing  bool
+	nextOutput int // index of the output to be offered first
+	// powerUsage is the power needed while producing. Assemblers without
+	// usage work without power.
+	powerUsage   float64
+	satisfaction float64
+}
+
+// SetSpeed sets the crafting speed. A production takes ProductionTime/speed
+// ticks, fractions are carried over to the next production.
+func (c *Assembler) SetSpeed(speed float64) {
+	if speed <= 0 {
+		return
+	}
+	c.speed = speed
+}
+
+func (c *Assembler) Speed() float64 {
+	return c.speed
+}
+
+// SetPowerUsage sets the power needed while producing. With less power
+// available, the assembler slows down proportionally.
+func (c *Assembler) SetPowerUsage(usage float64) {
+	c.powerUsage = Max(0, usage)
+}
+
+// PowerDemand returns the usage while producing, or able to start
+// producing in this tick.
+func (c *Assembler) PowerDemand() float64 {
+	if !c.producing && !c.canStartProduction() {
+		return 0
+	}
+	return c.powerUsage
 }
 
+func (c *Assembler) SetPowerSatisfaction(f float64) {
+	c.satisfaction = f
+}
+
+// effectiveSpeed returns the speed reduced by missing power.
+func (c *Assembler) effectiveSpeed() float64 {
+	if c.powerUsage <= 0 {
+		return c.speed
+	}
+	return c.speed * c.satisfaction
+}
+
+func (c *Assembler) isMachine() {}
+
 func (c *Assembler) Size() grid.Size {
-	return grid.S(1, 1)
+	return c.size
 }
 
 func (c *Assembler) Name() string {
@@ -52,43 +117,161 @@ func (c *Assembler) Info() []string {
 	info := []string{
 		fmt.Sprintf("Assembler: %s", c.name),
 		fmt.Sprintf("Receipt: %s", c.receipt.String()),
+		fmt.Sprintf("Speed: %g", c.speed),
+		fmt.Sprintf("Status: %s", c.Status()),
+	}
+	if c.powerUsage > 0 {
+		info = append(info, fmt.Sprintf("Power: %.1f (%.0f%%)", c.powerUsage, 100*c.satisfaction))
 	}
 	info = append(info, stock...)
+	for _, res := range c.leftovers.Resources() {
+		info = append(info, fmt.Sprintf("Leftover: %s: %d", res, c.leftovers.Amount(res)))
+	}
 	return info
 }
 
-func (c *Assembler) ProduceAtPositions(base grid.Position) []grid.Position {
-	return base.Neighbours()
+// SetReceipt reconfigures the assembler to another receipt. A running
+// production is cancelled and its inputs are returned to the input stocks.
+// Input stocks which are still needed are kept. All other items, including
+// produced outputs, become leftovers, which are produced before any output
+// of the new receipt.
+func (c *Assembler) SetReceipt(rec Receipt) {
+	left := map[Resource]int{}
+	if c.producing {
+		for res, cnt := range c.receipt.Input {
+			added := c.inStocks[res].Add(res, cnt)
+			left[res] += cnt - added
+		}
+		c.producing = false
+	}
+	c.progress = 0
+	inStocks := make(map[Resource]*Stock)
+	for res := range rec.Input {
+		if s, ok := c.inStocks[res]; ok {
+			inStocks[res] = s
+			continue
+		}
+		inStocks[res] = NewStock(c.inCapa)
+	}
+	for res, s := range c.inStocks {
+		if _, ok := inStocks[res]; ok {
+			continue
+		}
+		left[res] += s.Amount(res)
+	}
+	for _, res := range c.outStock.Resources() {
+		left[res] += c.outStock.Take(res, c.outStock.Amount(res))
+	}
+	for _, res := range c.leftovers.Resources() {
+		left[res] += c.leftovers.Amount(res)
+	}
+
+	var total int
+	for _, n := range left {
+		total += n
+	}
+	c.leftovers = NewStock(total)
+	for res, n := range left {
+		c.leftovers.Add(res, n)
+	}
+	c.receipt = rec
+	c.inStocks = inStocks
+	c.nextOutput = 0
+}
+
+func (c *Assembler) ProduceAtPositions(r grid.Rectangle) []grid.Position {
+	return r.Neighbours()
 }
 
-func (c *Assembler) ConsumeAtPositions(base grid.Position) []grid.Position {
-	return []grid.Position{base}
+func (c *Assembler) ConsumeAtPositions(r grid.Rectangle) []grid.Position {
+	return r.Positions()
 }
 
+// Tick works on the production for effectiveSpeed ticks. Productions
+// complete back to back within a tick, as long as the inputs last, and the
+// remaining progress is carried over to the next one. Progress is lost, when
+// no production can be started.
 func (c *Assembler) Tick() {
-	c.currTick++
-	if c.producing {
-		if c.currTick-c.lastProdTick >= c.receipt.ProductionTime {
-			c.outStock.Add(c.receipt.Output, 1)
-			c.producing = false
+	if !c.producing && !c.startProduction() {
+		c.progress = 0
+		return
+	}
+	c.progress += c.effectiveSpeed()
+	// tolerate rounding errors of the accumulated progress
+	for c.progress+1e-9 >= float64(c.receipt.ProductionTime) {
+		for _, out := range c.receipt.Outputs {
+			c.outStock.Add(out.Resource, out.Count)
 		}
-	} else if c.outStock.CanAdd(c.receipt.Output, 1) {
-		// see if we can produce something
-		for res, cnt := range c.receipt.Input {
-			if c.inStocks[res].Amount(res) < cnt {
-				return
-			}
+		c.progress = math.Max(0, c.progress-float64(c.receipt.ProductionTime))
+		c.producing = false
+		if !c.startProduction() {
+			c.progress = 0
+			return
 		}
-		// we have enough - take it from stocks
-		for res, cnt := range c.receipt.Input {
-			c.inStocks[res].Take(res, cnt)
+	}
+}
+
+// canStartProduction reports whether the inputs of one production are
+// available and the output fits.
+func (c *Assembler) canStartProduction() bool {
+	if !c.outStock.CanAdd(c.receipt.PrimaryOutput(), c.receipt.OutputCount()) {
+		return false
+	}
+	for res, cnt := range c.receipt.Input {
+		if c.inStocks[res].Amount(res) < cnt {
+			return false
+		}
+	}
+	return true
+}
+
+// startProduction takes the inputs of one production from stock, if it can
+// be started.
+func (c *Assembler) startProduction() bool {
+	if !c.canStartProduction() {
+		return false
+	}
+	for res, cnt := range c.receipt.Input {
+		c.inStocks[res].Take(res, cnt)
+	}
+	c.producing = true
+	return true
+}
+
+func (c *Assembler) Status() Status {
+	switch {
+	case c.producing:
+		return Status{Kind: StatusWorking}
+	case !c.outStock.CanAdd(c.receipt.PrimaryOutput(), c.receipt.OutputCount()):
+		return Status{Kind: StatusOutputBlocked}
+	}
+	var missing []Resource
+	empty := true
+	for res, cnt := range c.receipt.Input {
+		amount := c.inStocks[res].Amount(res)
+		if amount > 0 {
+			empty = false
 		}
-		//start production
-		c.producing = true
-		c.lastProdTick = c.currTick
+		if amount < cnt {
+			missing = append(missing, res)
+		}
+	}
+	switch {
+	case len(missing) == 0:
+		// production starts with the next tick
+		return Status{Kind: StatusWorking}
+	case empty:
+		return Status{Kind: StatusIdle}
+	default:
+		sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+		return Status{Kind: StatusInputStarved, Missing: missing}
 	}
 }
 
+func (c *Assembler) Receipt() Receipt {
+	return c.receipt
+}
+
 func (c *Assembler) ConsumeFrom(res Resource, dir grid.Direction) {
 	if !c.CanConsumeFrom(res, dir) {
 		return
@@ -108,19 +291,167 @@ func (c *Assembler) CanConsumeAny() bool {
 	return true
 }
 
+// offeredOutput returns the index of the output to be produced next. Outputs
+// in stock are offered in turns.
+func (c *Assembler) offeredOutput() (int, bool) {
+	n := len(c.receipt.Outputs)
+	for i := 0; i < n; i++ {
+		idx := (c.nextOutput + i) % n
+		if c.outStock.Amount(c.receipt.Outputs[idx].Resource) > 0 {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
 func (c *Assembler) Produce() (Resource, bool) {
-	if c.outStock.Amount(c.receipt.Output) > 0 {
-		//Log("%s: produce: %s", c.name, c.receipt.output)
-		c.outStock.Take(c.receipt.Output, 1)
-		return c.receipt.Output, true
+	res := c.Resource()
+	if res == None || !c.ProduceResource(res) {
+		return None, false
 	}
-	return None, false
+	return res, true
+}
+
+// Offers returns the leftovers and then the outputs in stock, starting with
+// the output whose turn it is.
+func (c *Assembler) Offers() []Resource {
+	ress := c.leftovers.Resources()
+	n := len(c.receipt.Outputs)
+	for i := 0; i < n; i++ {
+		res := c.receipt.Outputs[(c.nextOutput+i)%n].Resource
+		if c.outStock.Amount(res) > 0 {
+			ress = append(ress, res)
+		}
+	}
+	return ress
+}
+
+// ProduceResource hands out a leftover or output res. The turn passes to the
+// output following res.
+func (c *Assembler) ProduceResource(res Resource) bool {
+	if c.leftovers.Take(res, 1) > 0 {
+		return true
+	}
+	if c.outStock.Take(res, 1) == 0 {
+		return false
+	}
+	for i, out := range c.receipt.Outputs {
+		if out.Resource == res {
+			c.nextOutput = (i + 1) % len(c.receipt.Outputs)
+		}
+	}
+	return true
 }
 
 func (c *Assembler) CanProduce() bool {
-	return c.outStock.Amount(c.receipt.Output) > 0
+	if c.leftovers.TotalAmount() > 0 {
+		return true
+	}
+	_, ok := c.offeredOutput()
+	return ok
 }
 
+// Resource returns the leftover or output which is offered next.
 func (c *Assembler) Resource() Resource {
-	return c.receipt.Output
+	if ress := c.leftovers.Resources(); len(ress) > 0 {
+		return ress[0]
+	}
+	idx, ok := c.offeredOutput()
+	if !ok {
+		return None
+	}
+	return c.receipt.Outputs[idx].Resource
+}
+
+type assemblerJSON struct {
+	Name         string              `json:"name"`
+	Size         grid.Size           `json:"size"`
+	Receipt      Receipt             `json:"receipt"`
+	InCapa       int                 `json:"in_capa"` // missing in older saves
+	InStocks     map[Resource]*Stock `json:"in_stocks"`
+	OutStock     *Stock              `json:"out_stock"`
+	Leftovers    *Stock              `json:"leftovers"`
+	Speed        float64             `json:"speed"`
+	Progress     float64             `json:"progress"`
+	PowerUsage   float64             `json:"power_usage"`
+	Satisfaction float64             `json:"satisfaction"`
+	Producing    bool                `json:"producing"`
+	NextOutput   int                 `json:"next_output"`
+	// written before the crafting speed, read to restore running productions
+	LastProdTick int `json:"last_prod_tick,omitempty"`
+	CurrTick     int `json:"curr_tick,omitempty"`
+}
+
+func (c *Assembler) Kind() ObjectKind {
+	return KindAssembler
+}
+
+func (c *Assembler) MarshalJSON() ([]byte, error) {
+	return json.Marshal(assemblerJSON{
+		Name:         c.name,
+		Size:         c.size,
+		Receipt:      c.receipt,
+		InCapa:       c.inCapa,
+		InStocks:     c.inStocks,
+		OutStock:     c.outStock,
+		Leftovers:    c.leftovers,
+		Speed:        c.speed,
+		Progress:     c.progress,
+		PowerUsage:   c.powerUsage,
+		Satisfaction: c.satisfaction,
+		Producing:    c.producing,
+		NextOutput:   c.nextOutput,
+	})
+}
+
+func (c *Assembler) UnmarshalJSON(data []byte) error {
+	var aj assemblerJSON
+	err := json.Unmarshal(data, &aj)
+	if err != nil {
+		return err
+	}
+	for inRes := range aj.Receipt.Input {
+		if _, ok := aj.InStocks[inRes]; !ok {
+			return fmt.Errorf("missing in-stock for %q", inRes)
+		}
+	}
+	if aj.OutStock == nil {
+		return fmt.Errorf("missing out-stock")
+	}
+	if len(aj.Receipt.Outputs) == 0 {
+		return fmt.Errorf("receipt has no output")
+	}
+	if aj.Speed < 0 {
+		return fmt.Errorf("negative speed %g", aj.Speed)
+	}
+	if aj.Speed == 0 {
+		aj.Speed = 1
+	}
+	if aj.Leftovers == nil {
+		aj.Leftovers = NewStock(0)
+	}
+	if aj.InCapa == 0 {
+		for _, s := range aj.InStocks {
+			aj.InCapa = Max(aj.InCapa, s.capacity)
+		}
+	}
+	if aj.Producing && aj.Progress == 0 && aj.CurrTick > aj.LastProdTick {
+		aj.Progress = float64(aj.CurrTick - aj.LastProdTick)
+	}
+	*c = Assembler{
+		name:         aj.Name,
+		size:         aj.Size,
+		receipt:      aj.Receipt,
+		inCapa:       aj.InCapa,
+		inStocks:     aj.InStocks,
+		outStock:     aj.OutStock,
+		leftovers:    aj.Leftovers,
+		speed:        aj.Speed,
+		progress:     aj.Progress,
+		powerUsage:   aj.PowerUsage,
+		satisfaction: aj.Satisfaction,
+		producing:    aj.Producing,
+		nextOutput:   aj.NextOutput % len(aj.Receipt.Outputs),
+	}
+	return nil
 }
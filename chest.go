@@ -0,0 +1,170 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+var _ ProducerConsumer = &Chest{}
+var _ StatusReporter = &Chest{}
+
+// NewChest creates a chest with the given number of slots, each of which
+// stores one stack of a resource. With a filter other than None it only
+// accepts that resource.
+func NewChest(name string, slots int, filter Resource) *Chest {
+	return &Chest{
+		name:   name,
+		stock:  NewSlotStock(slots),
+		filter: filter,
+	}
+}
+
+// Chest is a passive buffer. It offers the resources in stock in turn to its
+// neighbours, except to other chests.
+type Chest struct {
+	name   string
+	stock  *Stock
+	filter Resource
+	next   int // index of the resource in stock, which is offered
+}
+
+func (c *Chest) isMachine() {}
+
+func (c *Chest) Size() grid.Size {
+	return grid.S(1, 1)
+}
+
+func (c *Chest) Name() string {
+	return c.name
+}
+
+func (c *Chest) Filter() Resource {
+	return c.filter
+}
+
+// SetFilter restricts the chest to res, or lifts the restriction with None.
+// Items already in stock are kept.
+func (c *Chest) SetFilter(res Resource) {
+	c.filter = res
+}
+
+func (c *Chest) Amount(res Resource) int {
+	return c.stock.Amount(res)
+}
+
+func (c *Chest) Info() []string {
+	info := []string{
+		fmt.Sprintf("Chest: %s", c.name),
+		fmt.Sprintf("Filter: %s", c.filter),
+		fmt.Sprintf("Slots: %d/%d", c.stock.UsedSlots(), c.stock.capacity),
+	}
+	for _, res := range c.stock.Resources() {
+		info = append(info, fmt.Sprintf("Stock: %s: %d", res, c.stock.Amount(res)))
+	}
+	return info
+}
+
+func (c *Chest) Tick() {
+	c.next++
+}
+
+func (c *Chest) Status() Status {
+	// a full chest is not blocked, as it has nothing to produce
+	switch {
+	case c.stock.TotalAmount() == 0, c.stock.Full():
+		return Status{Kind: StatusIdle}
+	default:
+		return Status{Kind: StatusWorking}
+	}
+}
+
+func (c *Chest) ConsumeAtPositions(r grid.Rectangle) []grid.Position {
+	return r.Positions()
+}
+
+func (c *Chest) ConsumeFrom(res Resource, dir grid.Direction) {
+	if !c.CanConsumeFrom(res, dir) {
+		return
+	}
+	c.stock.Add(res, 1)
+}
+
+func (c *Chest) CanConsumeFrom(res Resource, dir grid.Direction) bool {
+	if c.filter != None && res != c.filter {
+		return false
+	}
+	return c.stock.CanAdd(res, 1)
+}
+
+func (c *Chest) CanConsumeAny() bool {
+	return !c.stock.Full()
+}
+
+func (c *Chest) ProduceAtPositions(r grid.Rectangle) []grid.Position {
+	return r.Neighbours()
+}
+
+func (c *Chest) CanProduce() bool {
+	return c.stock.TotalAmount() > 0
+}
+
+func (c *Chest) Produce() (Resource, bool) {
+	res := c.Resource()
+	if res == None {
+		return None, false
+	}
+	c.stock.Take(res, 1)
+	return res, true
+}
+
+// Resource returns the resource offered in this tick.
+func (c *Chest) Resource() Resource {
+	ress := c.stock.Resources()
+	if len(ress) == 0 {
+		return None
+	}
+	return ress[c.next%len(ress)]
+}
+
+type chestJSON struct {
+	Name   string   `json:"name"`
+	Stock  *Stock   `json:"stock"`
+	Filter Resource `json:"filter"`
+	Next   int      `json:"next"`
+}
+
+func (c *Chest) Kind() ObjectKind {
+	return KindChest
+}
+
+func (c *Chest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(chestJSON{
+		Name:   c.name,
+		Stock:  c.stock,
+		Filter: c.filter,
+		Next:   c.next,
+	})
+}
+
+func (c *Chest) UnmarshalJSON(data []byte) error {
+	var cj chestJSON
+	err := json.Unmarshal(data, &cj)
+	if err != nil {
+		return err
+	}
+	if cj.Stock == nil {
+		return fmt.Errorf("missing stock")
+	}
+	if cj.Filter == "" {
+		cj.Filter = None
+	}
+	*c = Chest{
+		name:   cj.Name,
+		stock:  cj.Stock,
+		filter: cj.Filter,
+		next:   cj.Next,
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package minifac
+
+import (
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func TestChestFilter(t *testing.T) {
+	c := NewChest("chest", 2, Coal)
+	if c.CanConsumeFrom(Iron, grid.West) {
+		t.Fatalf("filtered chest takes %s", Iron)
+	}
+	want := 2 * Coal.StackSize()
+	repeat(func() { c.ConsumeFrom(Coal, grid.West) }, want+5)
+	if n := c.Amount(Coal); n != want {
+		t.Fatalf("amount: want %d, have %d", want, n)
+	}
+}
+
+func TestChestSlots(t *testing.T) {
+	c := NewChest("chest", 2, None)
+	c.ConsumeFrom(Coal, grid.West)
+	c.ConsumeFrom(Iron, grid.West)
+	// both slots are taken, but the stacks are not full
+	if c.CanConsumeFrom(Steel, grid.West) {
+		t.Fatalf("chest takes %s without a free slot", Steel)
+	}
+	if !c.CanConsumeFrom(Coal, grid.West) {
+		t.Fatalf("chest does not fill up the stack of %s", Coal)
+	}
+	if have := c.stock.UsedSlots(); have != 2 {
+		t.Fatalf("used slots: want %d, have %d", 2, have)
+	}
+}
+
+func TestChestBuffersBursts(t *testing.T) {
+	// a burst of 5 coal every 10 ticks reaches the finalizer through the chest
+	u := NewUniverse(grid.S(3, 1))
+	u.SetExplicitIO(false)
+	u.AddObject(NewIncarnationProducer("prod", Coal, NewRate(5, 10), 5), grid.P(0, 0))
+	u.AddObject(NewChest("chest", 20, None), grid.P(1, 0))
+	u.AddObject(NewFinalizer("fin", Coal), grid.P(2, 0))
+	repeat(u.Tick, 100)
+	if n := consumed(u, "fin"); n < 40 {
+		t.Fatalf("consumed: want at least %d, have %d", 40, n)
+	}
+}
+
+func TestChestsDoNotExchange(t *testing.T) {
+	for _, explicit := range []bool{false, true} {
+		u := NewUniverse(grid.S(2, 1))
+		u.SetExplicitIO(explicit)
+		c1, c2 := NewChest("chest_1", 2, None), NewChest("chest_2", 2, None)
+		u.AddObject(c1, grid.P(0, 0))
+		u.AddObject(c2, grid.P(1, 0))
+		for c1.CanConsumeAny() {
+			c1.ConsumeFrom(Coal, grid.West)
+		}
+		repeat(u.Tick, 5)
+		if n := c2.Amount(Coal); n != 0 {
+			t.Fatalf("explicit %t: coal in second chest: want %d, have %d", explicit, 0, n)
+		}
+		if s := c1.Status().Kind; s != StatusIdle {
+			t.Fatalf("explicit %t: status of full chest: want %s, have %s", explicit, StatusIdle, s)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 
 	"net/http"
@@ -13,12 +14,87 @@ import (
 )
 
 func main() {
+	mapFile := flag.String("map", "", "load the universe from this file (saved .json or text map) instead of the built-in layout")
+	resourcesFile := flag.String("resources", "", "register additional resources from this file")
+	receiptsFile := flag.String("receipts", "", "load the receipts from this file instead of the built-in ones")
+	directIO := flag.Bool("direct-io", false, "machines exchange items with any neighbour, not only with inserters")
+	generate := flag.Bool("generate", false, "generate the universe instead of using the built-in layout")
+	seed := flag.Int64("seed", 1, "seed of the generated universe")
+	genSize := flag.Int("size", 32, "width and height of the generated universe")
+	scenarioFile := flag.String("scenario", "", "play the scenario from this file")
+	research := flag.Bool("research", false, "unlock receipts and objects by research in labs")
+	techTreeFile := flag.String("techtree", "", "research the tech tree from this file instead of the built-in one (implies -research)")
+	flag.Parse()
+
+	if *resourcesFile != "" {
+		infos, err := minifac.LoadResourcesFile(*resourcesFile)
+		if err != nil {
+			log.Fatalf("load resources: %v", err)
+		}
+		if err := minifac.RegisterResources(infos); err != nil {
+			log.Fatalf("register resources: %v", err)
+		}
+	}
+	if *receiptsFile != "" {
+		recs, err := minifac.LoadReceiptsFile(*receiptsFile)
+		if err != nil {
+			log.Fatalf("load receipts: %v", err)
+		}
+		if err := minifac.SetReceipts(recs); err != nil {
+			log.Fatalf("set receipts: %v", err)
+		}
+	}
+
 	go func() {
 		http.ListenAndServe("localhost:6060", nil)
 	}()
 
-	uni := setupUniverse()
+	var uni *minifac.Universe
+	var run *minifac.ScenarioRun
+	switch {
+	case *scenarioFile != "":
+		sc, err := minifac.LoadScenarioFile(*scenarioFile)
+		if err != nil {
+			log.Fatalf("load scenario: %v", err)
+		}
+		run, err = sc.Start()
+		if err != nil {
+			log.Fatalf("start scenario: %v", err)
+		}
+		uni = run.Universe()
+	case *mapFile != "":
+		var err error
+		uni, err = minifac.LoadFile(*mapFile)
+		if err != nil {
+			log.Fatalf("load map: %v", err)
+		}
+	case *generate:
+		uni = minifac.Generate(grid.S(*genSize, *genSize), *seed)
+	default:
+		uni = setupUniverse()
+	}
+	if *directIO {
+		uni.SetExplicitIO(false)
+	}
+	if *research || *techTreeFile != "" {
+		techs := minifac.DefaultTechTree()
+		if *techTreeFile != "" {
+			var err error
+			techs, err = minifac.LoadTechTreeFile(*techTreeFile)
+			if err != nil {
+				log.Fatalf("load tech tree: %v", err)
+			}
+		}
+		r, err := minifac.NewResearch(techs)
+		if err != nil {
+			log.Fatalf("research: %v", err)
+		}
+		uni.SetResearch(r)
+	}
 	mfui := ui.New(uni)
+	if run != nil {
+		mfui.SetScenario(run)
+	}
 
 	ebiten.SetWindowSize(1024+ui.MenuWidth, 1024)
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
@@ -32,6 +108,8 @@ func main() {
 func setupUniverse() *minifac.Universe {
 	size := grid.S(16, 16)
 	u := minifac.NewUniverse(size)
+	// the layout has no inserters
+	u.SetExplicitIO(false)
 	for x := 0; x < 16; x++ {
 		u.AddObject(minifac.NewObstacle("wall", minifac.ObstacleWall), grid.P(x, 0))
 		u.AddObject(minifac.NewObstacle("wall", minifac.ObstacleWall), grid.P(x, 15))
@@ -70,6 +148,22 @@ func setupUniverse() *minifac.Universe {
 	u.AddObject(minifac.NewObstacle("wall", minifac.ObstacleWall), grid.P(4, 10))
 	u.AddObject(minifac.NewObstacle("wall", minifac.ObstacleWall), grid.P(5, 10))
 
+	// deposits to place miners on
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 2; y++ {
+			u.SetDeposit(grid.P(2+x, 12+y), minifac.Deposit{Resource: minifac.IronOre, Amount: 200})
+			u.SetDeposit(grid.P(9+x, 8+y), minifac.Deposit{Resource: minifac.Coal, Amount: 200})
+			u.SetDeposit(grid.P(11+x, 12+y), minifac.Deposit{Resource: minifac.Stone, Amount: 100})
+		}
+	}
+	// a lake and some rocks to build around
+	for y := 12; y < 15; y++ {
+		u.SetTerrain(grid.P(6, y), minifac.TerrainWater)
+		u.SetTerrain(grid.P(7, y), minifac.TerrainWater)
+	}
+	u.SetTerrain(grid.P(13, 6), minifac.TerrainRock)
+	u.SetTerrain(grid.P(13, 7), minifac.TerrainRock)
+
 	return u
 }
 
@@ -77,6 +171,7 @@ func setupUniverse2() *minifac.Universe {
 	size := grid.S(16, 16)
 
 	u := minifac.NewUniverse(size)
+	u.SetExplicitIO(false)
 	u.AddObject(minifac.NewIncarnationProducer("prod_iron_ore", minifac.IronOre, minifac.NewRate(1, 2), 2), grid.P(1, 1))
 	u.AddObject(minifac.NewConveyor("conv_ironore_1", grid.East, 1), grid.P(2, 1))
 
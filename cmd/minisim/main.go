@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mazzegi/minifac"
+)
+
+func main() {
+	mapFile := flag.String("map", "", "universe to simulate (saved .json or text map)")
+	ticks := flag.Int("ticks", 1000, "number of ticks to run")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	resourcesFile := flag.String("resources", "", "register additional resources from this file")
+	receiptsFile := flag.String("receipts", "", "load the receipts from this file instead of the built-in ones")
+	directIO := flag.Bool("direct-io", false, "machines exchange items with any neighbour, not only with inserters")
+	scenarioFile := flag.String("scenario", "", "run the scenario from this file on its map until it is won or lost, or for at most -ticks ticks")
+	flag.Parse()
+
+	if (*mapFile == "" && *scenarioFile == "") || *ticks <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *mapFile != "" && *scenarioFile != "" {
+		fmt.Fprintf(os.Stderr, "-map and -scenario cannot be combined, the scenario brings its own map\n")
+		os.Exit(2)
+	}
+	if *resourcesFile != "" {
+		infos, err := minifac.LoadResourcesFile(*resourcesFile)
+		if err == nil {
+			err = minifac.RegisterResources(infos)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "load resources: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *receiptsFile != "" {
+		recs, err := minifac.LoadReceiptsFile(*receiptsFile)
+		if err == nil {
+			err = minifac.SetReceipts(recs)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "load receipts: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var run *minifac.ScenarioRun
+	if *scenarioFile != "" {
+		sc, err := minifac.LoadScenarioFile(*scenarioFile)
+		if err == nil {
+			run, err = sc.Start()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "scenario: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var u *minifac.Universe
+	if run != nil {
+		u = run.Universe()
+	} else {
+		var err error
+		u, err = minifac.LoadFile(*mapFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "load map: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *directIO {
+		u.SetExplicitIO(false)
+	}
+
+	start := time.Now()
+	ran := 0
+	for ; ran < *ticks; ran++ {
+		if run == nil {
+			u.Tick()
+			continue
+		}
+		if run.Status() != minifac.ScenarioRunning {
+			break
+		}
+		run.Tick()
+	}
+	rep := newReport(u, ran, time.Since(start))
+	if run != nil {
+		rep.Scenario = newScenarioReport(run)
+	}
+
+	var err error
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(rep)
+	} else {
+		err = rep.write(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "write report: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+type producerReport struct {
+	Name     string           `json:"name"`
+	Resource minifac.Resource `json:"resource"`
+	Produced int              `json:"produced"`
+	PerTick  float64          `json:"per_tick"`
+}
+
+type consumerReport struct {
+	Name     string             `json:"name"`
+	Kind     minifac.ObjectKind `json:"kind"`
+	Consumed int                `json:"consumed"`
+	PerTick  float64            `json:"per_tick"`
+}
+
+type assemblerReport struct {
+	Name        string  `json:"name"`
+	Receipt     string  `json:"receipt"`
+	Produced    int     `json:"produced"`
+	PerTick     float64 `json:"per_tick"`
+	Utilization float64 `json:"utilization"`
+	Blocked     float64 `json:"blocked"`
+}
+
+type networkReport struct {
+	ID           int     `json:"id"`
+	Poles        int     `json:"poles"`
+	Supply       float64 `json:"supply"`
+	Demand       float64 `json:"demand"`
+	Satisfaction float64 `json:"satisfaction"`
+}
+
+type scenarioReport struct {
+	Name   string                 `json:"name"`
+	Status minifac.ScenarioStatus `json:"status"`
+	Goals  []minifac.GoalProgress `json:"goals"`
+}
+
+func newScenarioReport(run *minifac.ScenarioRun) *scenarioReport {
+	return &scenarioReport{
+		Name:   run.Scenario().Name,
+		Status: run.Status(),
+		Goals:  run.Goals(),
+	}
+}
+
+type report struct {
+	Ticks      int               `json:"ticks"`
+	ElapsedMS  float64           `json:"elapsed_ms"`
+	Producers  []producerReport  `json:"producers"`
+	Consumers  []consumerReport  `json:"consumers"`
+	Assemblers []assemblerReport `json:"assemblers"`
+	Networks   []networkReport   `json:"networks"`
+	Scenario   *scenarioReport   `json:"scenario,omitempty"`
+	// technologies completed so far, if the universe has research
+	Researched []string `json:"researched,omitempty"`
+}
+
+func newReport(u *minifac.Universe, ticks int, elapsed time.Duration) *report {
+	rep := &report{
+		Ticks:      ticks,
+		ElapsedMS:  float64(elapsed.Microseconds()) / 1000,
+		Producers:  []producerReport{},
+		Consumers:  []consumerReport{},
+		Assemblers: []assemblerReport{},
+		Networks:   []networkReport{},
+	}
+	perTick := func(n int) float64 {
+		return float64(n) / float64(ticks)
+	}
+	for _, gobj := range u.AllObjects() {
+		stats, ok := u.Stats(gobj.Value)
+		if !ok {
+			stats = &minifac.ObjectStats{}
+		}
+		total := stats.Total
+		switch obj := gobj.Value.(type) {
+		case *minifac.IncarnationProducer:
+			rep.Producers = append(rep.Producers, producerReport{
+				Name:     obj.Name(),
+				Resource: obj.Resource(),
+				Produced: total.Produced,
+				PerTick:  perTick(total.Produced),
+			})
+		case *minifac.Finalizer:
+			rep.Consumers = append(rep.Consumers, consumerReport{
+				Name:     obj.Name(),
+				Kind:     obj.Kind(),
+				Consumed: total.Consumed,
+				PerTick:  perTick(total.Consumed),
+			})
+		case *minifac.Trashbin:
+			rep.Consumers = append(rep.Consumers, consumerReport{
+				Name:     obj.Name(),
+				Kind:     obj.Kind(),
+				Consumed: total.Consumed,
+				PerTick:  perTick(total.Consumed),
+			})
+		case *minifac.Assembler:
+			rep.Assemblers = append(rep.Assemblers, assemblerReport{
+				Name:        obj.Name(),
+				Receipt:     obj.Receipt().String(),
+				Produced:    total.Produced,
+				PerTick:     perTick(total.Produced),
+				Utilization: stats.Utilization(),
+				Blocked:     stats.Blocked(),
+			})
+		}
+	}
+	if r, ok := u.Research(); ok {
+		rep.Researched = r.Completed()
+	}
+	for _, n := range u.PowerNetworks() {
+		rep.Networks = append(rep.Networks, networkReport{
+			ID:           n.ID,
+			Poles:        len(n.Poles),
+			Supply:       n.Supply,
+			Demand:       n.Demand,
+			Satisfaction: n.Satisfaction(),
+		})
+	}
+	return rep
+}
+
+func (rep *report) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Ticks: %d (%.2fms)\n", rep.Ticks, rep.ElapsedMS)
+
+	fmt.Fprintf(tw, "\nProducers\nname\tresource\tproduced\titems/tick\n")
+	for _, p := range rep.Producers {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.3f\n", p.Name, p.Resource, p.Produced, p.PerTick)
+	}
+	fmt.Fprintf(tw, "\nConsumers\nname\tkind\tconsumed\titems/tick\n")
+	for _, c := range rep.Consumers {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.3f\n", c.Name, c.Kind, c.Consumed, c.PerTick)
+	}
+	fmt.Fprintf(tw, "\nAssemblers\nname\treceipt\tproduced\titems/tick\tutilization\tblocked\n")
+	for _, a := range rep.Assemblers {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.3f\t%.1f%%\t%.1f%%\n", a.Name, a.Receipt, a.Produced, a.PerTick, a.Utilization*100, a.Blocked*100)
+	}
+	if len(rep.Networks) > 0 {
+		fmt.Fprintf(tw, "\nPower networks (last tick)\nid\tpoles\tsupply\tdemand\tsatisfaction\n")
+		for _, n := range rep.Networks {
+			fmt.Fprintf(tw, "%d\t%d\t%.1f\t%.1f\t%.1f%%\n", n.ID, n.Poles, n.Supply, n.Demand, n.Satisfaction*100)
+		}
+	}
+	if len(rep.Researched) > 0 {
+		fmt.Fprintf(tw, "\nResearched: %s\n", strings.Join(rep.Researched, ", "))
+	}
+	if rep.Scenario != nil {
+		fmt.Fprintf(tw, "\nScenario %s: %s\ngoal\tstatus\tdelivered\trate\tdone at\n", rep.Scenario.Name, rep.Scenario.Status)
+		for _, g := range rep.Scenario.Goals {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%.3f\t%d\n", g.Goal, g.Status, g.Delivered, g.Rate, g.DoneAt)
+		}
+	}
+	return tw.Flush()
+}
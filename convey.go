@@ -1,27 +1,72 @@
 package minifac
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/mazzegi/minifac/grid"
 )
 
 var _ ProducerConsumer = &Conveyor{}
+var _ StatusReporter = &Conveyor{}
 
+// ConveyorTier is a class of conveyors with the same speed and capacity.
+type ConveyorTier struct {
+	Name         string
+	TicksPerTile int
+	Capacity     int
+}
+
+var ConveyorTiers = []ConveyorTier{
+	{Name: "slow", TicksPerTile: 4, Capacity: 2},
+	{Name: "normal", TicksPerTile: 2, Capacity: 2},
+	{Name: "fast", TicksPerTile: 1, Capacity: 2},
+}
+
+func LookupConveyorTier(name string) (ConveyorTier, bool) {
+	for _, t := range ConveyorTiers {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return ConveyorTier{}, false
+}
+
+// NewConveyor creates a conveyor, which moves items by one tile per tick.
 func NewConveyor(name string, dir grid.Direction, capa int) *Conveyor {
+	return NewConveyorWithSpeed(name, dir, capa, 1)
+}
+
+// NewConveyorWithSpeed creates a conveyor, on which items need ticksPerTile
+// ticks to travel to the next tile.
+func NewConveyorWithSpeed(name string, dir grid.Direction, capa int, ticksPerTile int) *Conveyor {
 	return &Conveyor{
-		name:     name,
-		dir:      dir,
-		capacity: capa,
-		buffer:   NewQueue[Resource](),
+		name:         name,
+		dir:          dir,
+		capacity:     capa,
+		ticksPerTile: ticksPerTile,
+		buffer:       NewQueue[*conveyorItem](),
 	}
 }
 
+func NewConveyorOfTier(name string, dir grid.Direction, tier ConveyorTier) *Conveyor {
+	return NewConveyorWithSpeed(name, dir, tier.Capacity, tier.TicksPerTile)
+}
+
+// conveyorItem is an item on a conveyor. It may leave the conveyor, when
+// its progress reaches the ticks per tile.
+type conveyorItem struct {
+	Resource Resource `json:"resource"`
+	Progress int      `json:"progress"`
+}
+
 type Conveyor struct {
-	name     string
-	dir      grid.Direction
-	capacity int
-	buffer   *Queue[Resource]
+	name         string
+	dir          grid.Direction
+	capacity     int
+	ticksPerTile int
+	buffer       *Queue[*conveyorItem]
+	waiting      int // ticks the front item is waiting to be moved on
 }
 
 func (c *Conveyor) Size() grid.Size {
@@ -32,27 +77,52 @@ func (c *Conveyor) Dir() grid.Direction {
 	return c.dir
 }
 
-func (c *Conveyor) ProduceAtPositions(base grid.Position) []grid.Position {
-	var pos grid.Position
-	switch c.dir {
-	case grid.North:
-		pos = grid.P(base.X, base.Y-1)
-	case grid.East:
-		pos = grid.P(base.X+1, base.Y)
-	case grid.South:
-		pos = grid.P(base.X, base.Y+1)
-	default: //West
-		pos = grid.P(base.X-1, base.Y)
+func (c *Conveyor) TicksPerTile() int {
+	return c.ticksPerTile
+}
+
+// SetTier changes speed and capacity. Items exceeding the new capacity stay
+// on the conveyor.
+func (c *Conveyor) SetTier(tier ConveyorTier) {
+	c.ticksPerTile = tier.TicksPerTile
+	c.capacity = tier.Capacity
+	for _, item := range c.buffer.Values() {
+		item.Progress = Min(item.Progress, c.ticksPerTile)
 	}
-	return []grid.Position{pos}
 }
 
-func (c *Conveyor) ConsumeAtPositions(base grid.Position) []grid.Position {
-	return []grid.Position{base}
+// Tier returns the tier matching speed and capacity of the conveyor.
+func (c *Conveyor) Tier() (ConveyorTier, bool) {
+	for _, t := range ConveyorTiers {
+		if t.TicksPerTile == c.ticksPerTile && t.Capacity == c.capacity {
+			return t, true
+		}
+	}
+	return ConveyorTier{}, false
+}
+
+func (c *Conveyor) ProduceAtPositions(r grid.Rectangle) []grid.Position {
+	return []grid.Position{r.Position.Neighbour(c.dir)}
+}
+
+func (c *Conveyor) ConsumeAtPositions(r grid.Rectangle) []grid.Position {
+	return []grid.Position{r.Position}
 }
 
 func (c *Conveyor) Tick() {
+	for _, item := range c.buffer.Values() {
+		if item.Progress < c.ticksPerTile {
+			item.Progress++
+		}
+	}
+	if c.frontReady() {
+		c.waiting++
+	}
+}
 
+func (c *Conveyor) frontReady() bool {
+	item, ok := c.buffer.Peek()
+	return ok && item.Progress >= c.ticksPerTile
 }
 
 func (c *Conveyor) Name() string {
@@ -62,6 +132,21 @@ func (c *Conveyor) Name() string {
 func (c *Conveyor) Info() []string {
 	return []string{
 		fmt.Sprintf("Conveyor: %s", c.name),
+		fmt.Sprintf("Status  : %s", c.Status()),
+		fmt.Sprintf("Speed   : %d ticks/tile", c.ticksPerTile),
+		fmt.Sprintf("Items   : %d/%d", c.buffer.Len(), c.capacity),
+	}
+}
+
+func (c *Conveyor) Status() Status {
+	switch {
+	case c.buffer.Len() == 0:
+		return Status{Kind: StatusIdle}
+	case c.waiting > 0:
+		// the front item was here before the last transport and did not move
+		return Status{Kind: StatusOutputBlocked}
+	default:
+		return Status{Kind: StatusWorking}
 	}
 }
 
@@ -69,7 +154,7 @@ func (c *Conveyor) ConsumeFrom(res Resource, dir grid.Direction) {
 	if !c.CanConsumeFrom(res, dir) {
 		return
 	}
-	c.buffer.Enqueue(res)
+	c.buffer.Enqueue(&conveyorItem{Resource: res})
 }
 
 func (c *Conveyor) CanConsumeFrom(res Resource, dir grid.Direction) bool {
@@ -84,18 +169,81 @@ func (c *Conveyor) CanConsumeAny() bool {
 }
 
 func (c *Conveyor) Produce() (Resource, bool) {
-	res, ok := c.buffer.Dequeue()
-	return res, ok
+	if !c.frontReady() {
+		return None, false
+	}
+	item, _ := c.buffer.Dequeue()
+	c.waiting = 0
+	return item.Resource, true
 }
 
 func (c *Conveyor) CanProduce() bool {
-	return c.buffer.Len() > 0
+	return c.frontReady()
 }
 
+// Resource returns the resource of the front item, even if it has not yet
+// arrived at the end of the conveyor.
 func (c *Conveyor) Resource() Resource {
-	res, ok := c.buffer.Peek()
+	item, ok := c.buffer.Peek()
 	if !ok {
 		return None
 	}
-	return res
+	return item.Resource
+}
+
+type conveyorJSON struct {
+	Name         string                `json:"name"`
+	Dir          grid.Direction        `json:"dir"`
+	Capacity     int                   `json:"capacity"`
+	TicksPerTile int                   `json:"ticks_per_tile"`
+	Items        *Queue[*conveyorItem] `json:"items"`
+	Waiting      int                   `json:"waiting"`
+	// written before conveyor speeds, items of which may leave right away
+	Buffer *Queue[Resource] `json:"buffer,omitempty"`
+}
+
+func (c *Conveyor) Kind() ObjectKind {
+	return KindConveyor
+}
+
+func (c *Conveyor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(conveyorJSON{
+		Name:         c.name,
+		Dir:          c.dir,
+		Capacity:     c.capacity,
+		TicksPerTile: c.ticksPerTile,
+		Items:        c.buffer,
+		Waiting:      c.waiting,
+	})
+}
+
+func (c *Conveyor) UnmarshalJSON(data []byte) error {
+	var cj conveyorJSON
+	err := json.Unmarshal(data, &cj)
+	if err != nil {
+		return err
+	}
+	if cj.TicksPerTile == 0 {
+		cj.TicksPerTile = 1
+	}
+	if cj.TicksPerTile < 0 {
+		return fmt.Errorf("negative ticks per tile %d", cj.TicksPerTile)
+	}
+	*c = *NewConveyorWithSpeed(cj.Name, cj.Dir, cj.Capacity, cj.TicksPerTile)
+	if cj.Items == nil && cj.Buffer != nil {
+		cj.Items = NewQueue[*conveyorItem]()
+		for _, res := range cj.Buffer.Values() {
+			cj.Items.Enqueue(&conveyorItem{Resource: res, Progress: cj.TicksPerTile})
+		}
+	}
+	if cj.Items != nil {
+		for _, item := range cj.Items.Values() {
+			if item == nil {
+				return fmt.Errorf("invalid item")
+			}
+		}
+		c.buffer = cj.Items
+	}
+	c.waiting = cj.Waiting
+	return nil
 }
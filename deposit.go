@@ -0,0 +1,64 @@
+package minifac
+
+import (
+	"fmt"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+// Deposit is a finite amount of a resource in the ground, which is
+// extracted by miners.
+type Deposit struct {
+	Resource Resource `json:"resource"`
+	Amount   int      `json:"amount"`
+}
+
+// Empty reports whether nothing is left to extract.
+func (d Deposit) Empty() bool {
+	return d.Resource == "" || d.Resource == None || d.Amount <= 0
+}
+
+// SetDeposit places a deposit at p, replacing an existing one, and turns the
+// terrain at p into ore. A deposit with no amount removes it, but leaves the
+// terrain as it is.
+func (u *Universe) SetDeposit(p grid.Position, d Deposit) error {
+	if !u.ContainsPosition(p) {
+		return fmt.Errorf("position %s is out of bounds", p)
+	}
+	if d.Empty() {
+		u.deposits.Set(p, Deposit{})
+		return nil
+	}
+	u.deposits.Set(p, d)
+	u.terrain.Set(p, TerrainOre)
+	return nil
+}
+
+// DepositAt returns the deposit at p, if there is one left.
+func (u *Universe) DepositAt(p grid.Position) (Deposit, bool) {
+	d := u.deposits.At(p)
+	if d.Empty() {
+		return Deposit{}, false
+	}
+	return d, true
+}
+
+// PositionedDeposit is a deposit together with its position.
+type PositionedDeposit struct {
+	Position grid.Position `json:"position"`
+	Deposit
+}
+
+// AllDeposits returns all deposits, which are not depleted, row by row.
+func (u *Universe) AllDeposits() []PositionedDeposit {
+	var pds []PositionedDeposit
+	size := u.Size()
+	for y := 0; y < size.DY; y++ {
+		for x := 0; x < size.DX; x++ {
+			if d, ok := u.DepositAt(grid.P(x, y)); ok {
+				pds = append(pds, PositionedDeposit{Position: grid.P(x, y), Deposit: d})
+			}
+		}
+	}
+	return pds
+}
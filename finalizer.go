@@ -1,6 +1,7 @@
 package minifac
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/mazzegi/minifac/grid"
@@ -18,7 +19,6 @@ func NewFinalizer(name string, res Resource) *Finalizer {
 type Finalizer struct {
 	name     string
 	resource Resource
-	total    int
 }
 
 func (c *Finalizer) Size() grid.Size {
@@ -29,8 +29,8 @@ func (c *Finalizer) Resource() Resource {
 	return c.resource
 }
 
-func (c *Finalizer) ConsumeAtPositions(base grid.Position) []grid.Position {
-	return []grid.Position{base}
+func (c *Finalizer) ConsumeAtPositions(r grid.Rectangle) []grid.Position {
+	return r.Positions()
 }
 
 func (c *Finalizer) Tick() {
@@ -44,15 +44,11 @@ func (c *Finalizer) Name() string {
 func (c *Finalizer) Info() []string {
 	return []string{
 		fmt.Sprintf("Finalizer: %s: %s", c.name, c.resource),
-		fmt.Sprintf("Consumed: %d", c.total),
 	}
 }
 
 func (c *Finalizer) ConsumeFrom(res Resource, dir grid.Direction) {
-	if !c.CanConsumeFrom(res, dir) {
-		return
-	}
-	c.total++
+
 }
 
 func (c *Finalizer) CanConsumeFrom(res Resource, dir grid.Direction) bool {
@@ -62,3 +58,32 @@ func (c *Finalizer) CanConsumeFrom(res Resource, dir grid.Direction) bool {
 func (c *Finalizer) CanConsumeAny() bool {
 	return true
 }
+
+type finalizerJSON struct {
+	Name     string   `json:"name"`
+	Resource Resource `json:"resource"`
+}
+
+func (c *Finalizer) Kind() ObjectKind {
+	return KindFinalizer
+}
+
+func (c *Finalizer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(finalizerJSON{
+		Name:     c.name,
+		Resource: c.resource,
+	})
+}
+
+func (c *Finalizer) UnmarshalJSON(data []byte) error {
+	var fj finalizerJSON
+	err := json.Unmarshal(data, &fj)
+	if err != nil {
+		return err
+	}
+	*c = Finalizer{
+		name:     fj.Name,
+		resource: fj.Resource,
+	}
+	return nil
+}
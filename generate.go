@@ -0,0 +1,143 @@
+package minifac
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+// generatedOres are the resources deposits are generated for.
+var generatedOres = []Resource{Coal, IronOre, Stone}
+
+// StartArea returns the area in the center of a generated universe of the
+// given size, which is kept free.
+func StartArea(size grid.Size) grid.Rectangle {
+	s := grid.S(Min(8, size.DX/3), Min(8, size.DY/3))
+	return grid.R(grid.P((size.DX-s.DX)/2, (size.DY-s.DY)/2), s)
+}
+
+// Generate creates a universe surrounded by walls, with clusters of walls,
+// lakes and rock, and deposits of coal, iron ore and stone. The start area is
+// kept free, and all deposits can be reached from it without crossing
+// obstacles, water or rock. The same size and seed create the same universe.
+// Universes smaller than 3x3 are left empty.
+func Generate(size grid.Size, seed int64) *Universe {
+	rnd := rand.New(rand.NewSource(seed))
+	u := NewUniverse(size)
+	if size.DX < 3 || size.DY < 3 {
+		return u
+	}
+	start := StartArea(size)
+	inner := grid.R(grid.P(1, 1), grid.S(size.DX-2, size.DY-2))
+
+	wall := func(p grid.Position) {
+		u.AddObject(NewObstacle(fmt.Sprintf("wall_%d_%d", p.X, p.Y), ObstacleWall), p)
+	}
+	for x := 0; x < size.DX; x++ {
+		wall(grid.P(x, 0))
+		wall(grid.P(x, size.DY-1))
+	}
+	for y := 1; y < size.DY-1; y++ {
+		wall(grid.P(0, y))
+		wall(grid.P(size.DX-1, y))
+	}
+
+	// obstacles
+	clusters := Max(1, size.DX*size.DY/100)
+	for i := 0; i < clusters; i++ {
+		for _, p := range blob(rnd, randomPosition(rnd, inner), 4+rnd.Intn(12)) {
+			if !inner.Contains(p) || start.Grow(1).Contains(p) {
+				continue
+			}
+			if _, occ := u.ObjectAt(p); occ {
+				continue
+			}
+			switch i % 3 {
+			case 0:
+				wall(p)
+			case 1:
+				u.SetTerrain(p, TerrainWater)
+			default:
+				u.SetTerrain(p, TerrainRock)
+			}
+		}
+	}
+
+	// deposits, only on tiles reachable from the start area
+	reach := u.reachableFrom(start.Position)
+	var free []grid.Position
+	for _, p := range inner.Positions() {
+		if reach.At(p) && !start.Contains(p) {
+			free = append(free, p)
+		}
+	}
+	if len(free) == 0 {
+		return u
+	}
+	patches := Max(1, size.DX*size.DY/400)
+	for _, res := range generatedOres {
+		for i := 0; i < patches; i++ {
+			center := free[rnd.Intn(len(free))]
+			amount := 100 + 10*rnd.Intn(21)
+			for _, p := range blob(rnd, center, 6+rnd.Intn(10)) {
+				if !reach.At(p) || start.Contains(p) {
+					continue
+				}
+				if _, ok := u.DepositAt(p); ok {
+					continue
+				}
+				u.SetDeposit(p, Deposit{Resource: res, Amount: amount})
+			}
+		}
+	}
+	return u
+}
+
+func randomPosition(rnd *rand.Rand, r grid.Rectangle) grid.Position {
+	return grid.P(r.X+rnd.Intn(r.DX), r.Y+rnd.Intn(r.DY))
+}
+
+// blob returns the positions visited by a random walk of n steps from p,
+// which may contain duplicates.
+func blob(rnd *rand.Rand, p grid.Position, n int) []grid.Position {
+	poss := []grid.Position{p}
+	for i := 0; i < n; i++ {
+		p = p.Neighbours()[rnd.Intn(4)]
+		poss = append(poss, p)
+	}
+	return poss
+}
+
+// passable reports whether p is free and its terrain can be built on.
+func (u *Universe) passable(p grid.Position) bool {
+	if !u.ContainsPosition(p) {
+		return false
+	}
+	if _, occ := u.ObjectAt(p); occ {
+		return false
+	}
+	t := u.TerrainAt(p)
+	return t == TerrainGround || t == TerrainOre
+}
+
+// reachableFrom marks all passable tiles, which are connected to p.
+func (u *Universe) reachableFrom(p grid.Position) *grid.Layer[bool] {
+	reach := grid.NewLayer[bool](u.Size())
+	if !u.passable(p) {
+		return reach
+	}
+	reach.Set(p, true)
+	queue := []grid.Position{p}
+	for len(queue) > 0 {
+		p, queue = queue[0], queue[1:]
+		for _, n := range p.Neighbours() {
+			if reach.At(n) || !u.passable(n) {
+				continue
+			}
+			reach.Set(n, true)
+			queue = append(queue, n)
+		}
+	}
+	return reach
+}
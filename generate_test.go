@@ -0,0 +1,38 @@
+package minifac
+
+import (
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func TestGenerate(t *testing.T) {
+	size := grid.S(40, 30)
+	u := Generate(size, 42)
+	if want, have := saveString(t, u), saveString(t, Generate(size, 42)); have != want {
+		t.Fatalf("same seed creates different universes")
+	}
+	if saveString(t, u) == saveString(t, Generate(size, 43)) {
+		t.Fatalf("different seeds create the same universe")
+	}
+
+	start := StartArea(size)
+	for _, p := range start.Positions() {
+		if !u.passable(p) {
+			t.Fatalf("start area at %s is not free", p)
+		}
+	}
+	reach := u.reachableFrom(start.Position)
+	deposits := map[Resource]int{}
+	for _, pd := range u.AllDeposits() {
+		if !reach.At(pd.Position) {
+			t.Fatalf("deposit at %s is not reachable", pd.Position)
+		}
+		deposits[pd.Resource]++
+	}
+	for _, res := range generatedOres {
+		if deposits[res] == 0 {
+			t.Fatalf("no deposit of %s", res)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+var _ Consumer = &Generator{}
+var _ PowerSupplier = &Generator{}
+var _ StatusReporter = &Generator{}
+
+// NewGenerator creates a generator, which burns coal to provide power. One
+// item of coal lasts for burnTicks ticks at full load.
+func NewGenerator(name string, power float64, burnTicks int, fuelCapa int) *Generator {
+	return &Generator{
+		name:      name,
+		power:     power,
+		burnTicks: burnTicks,
+		fuel:      NewStock(fuelCapa),
+	}
+}
+
+type Generator struct {
+	name      string
+	power     float64
+	burnTicks int
+	fuel      *Stock
+	energy    float64 // energy left of the burning fuel
+	load      float64
+}
+
+func (g *Generator) Size() grid.Size {
+	return grid.S(1, 1)
+}
+
+func (g *Generator) Name() string {
+	return g.name
+}
+
+func (g *Generator) Info() []string {
+	return []string{
+		fmt.Sprintf("Generator: %s", g.name),
+		fmt.Sprintf("Status: %s", g.Status()),
+		fmt.Sprintf("Power: %.1f", g.power),
+		fmt.Sprintf("Load: %.0f%%", 100*g.load),
+		fmt.Sprintf("Fuel: %s: %d", Coal, g.fuel.Amount(Coal)),
+	}
+}
+
+func (g *Generator) Tick() {
+	// the load is a share of the supply, which may be limited by the fuel
+	need := g.PowerSupply() * g.load
+	if need <= 0 {
+		return
+	}
+	if g.energy < need && g.fuel.Take(Coal, 1) > 0 {
+		g.energy += g.power * float64(g.burnTicks)
+	}
+	g.energy = Max(0, g.energy-need)
+}
+
+// PowerSupply returns the power, but not more than the energy left and the
+// energy of the one item of coal, which may be burnt in the coming tick.
+func (g *Generator) PowerSupply() float64 {
+	available := g.energy
+	if g.fuel.Amount(Coal) > 0 {
+		available += g.power * float64(g.burnTicks)
+	}
+	return Min(g.power, available)
+}
+
+func (g *Generator) SetPowerLoad(f float64) {
+	g.load = f
+}
+
+func (g *Generator) Status() Status {
+	switch {
+	case g.PowerSupply() == 0:
+		return Status{Kind: StatusInputStarved, Missing: []Resource{Coal}}
+	case g.load > 0:
+		return Status{Kind: StatusWorking}
+	default:
+		return Status{Kind: StatusIdle}
+	}
+}
+
+func (g *Generator) ConsumeAtPositions(r grid.Rectangle) []grid.Position {
+	return r.Positions()
+}
+
+func (g *Generator) ConsumeFrom(res Resource, dir grid.Direction) {
+	if !g.CanConsumeFrom(res, dir) {
+		return
+	}
+	g.fuel.Add(res, 1)
+}
+
+func (g *Generator) CanConsumeFrom(res Resource, dir grid.Direction) bool {
+	return res == Coal && g.fuel.CanAdd(res, 1)
+}
+
+func (g *Generator) CanConsumeAny() bool {
+	return g.fuel.CanAdd(Coal, 1)
+}
+
+type generatorJSON struct {
+	Name      string  `json:"name"`
+	Power     float64 `json:"power"`
+	BurnTicks int     `json:"burn_ticks"`
+	Fuel      *Stock  `json:"fuel"`
+	Energy    float64 `json:"energy"`
+	Load      float64 `json:"load"`
+}
+
+func (g *Generator) Kind() ObjectKind {
+	return KindGenerator
+}
+
+func (g *Generator) MarshalJSON() ([]byte, error) {
+	return json.Marshal(generatorJSON{
+		Name:      g.name,
+		Power:     g.power,
+		BurnTicks: g.burnTicks,
+		Fuel:      g.fuel,
+		Energy:    g.energy,
+		Load:      g.load,
+	})
+}
+
+func (g *Generator) UnmarshalJSON(data []byte) error {
+	var gj generatorJSON
+	err := json.Unmarshal(data, &gj)
+	if err != nil {
+		return err
+	}
+	if gj.Fuel == nil {
+		return fmt.Errorf("missing fuel")
+	}
+	if gj.BurnTicks <= 0 {
+		return fmt.Errorf("non-positive burn ticks %d", gj.BurnTicks)
+	}
+	*g = Generator{
+		name:      gj.Name,
+		power:     gj.Power,
+		burnTicks: gj.BurnTicks,
+		fuel:      gj.Fuel,
+		energy:    gj.Energy,
+		load:      gj.Load,
+	}
+	return nil
+}
@@ -3,8 +3,6 @@ package grid
 import (
 	"fmt"
 	"sort"
-
-	"golang.org/x/exp/maps"
 )
 
 type Direction byte
@@ -17,6 +15,80 @@ const (
 	West
 )
 
+var directionNames = map[Direction]string{
+	None:  "none",
+	North: "north",
+	East:  "east",
+	South: "south",
+	West:  "west",
+}
+
+func (d Direction) String() string {
+	if s, ok := directionNames[d]; ok {
+		return s
+	}
+	return fmt.Sprintf("direction(%d)", d)
+}
+
+func ParseDirection(s string) (Direction, error) {
+	for d, ds := range directionNames {
+		if ds == s {
+			return d, nil
+		}
+	}
+	return None, fmt.Errorf("invalid direction %q", s)
+}
+
+func (d Direction) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Direction) UnmarshalText(text []byte) error {
+	pd, err := ParseDirection(string(text))
+	if err != nil {
+		return err
+	}
+	*d = pd
+	return nil
+}
+
+// Opposite returns the reverse direction.
+func (d Direction) Opposite() Direction {
+	switch d {
+	case North:
+		return South
+	case East:
+		return West
+	case South:
+		return North
+	case West:
+		return East
+	default:
+		return None
+	}
+}
+
+// Left returns the direction rotated counter-clockwise by 90 degrees.
+func (d Direction) Left() Direction {
+	switch d {
+	case North:
+		return West
+	case East:
+		return North
+	case South:
+		return East
+	case West:
+		return South
+	default:
+		return None
+	}
+}
+
+// Right returns the direction rotated clockwise by 90 degrees.
+func (d Direction) Right() Direction {
+	return d.Left().Opposite()
+}
+
 func P(x, y int) Position {
 	return Position{x, y}
 }
@@ -45,7 +117,24 @@ func DirectionFrom(pos Position, from Position) Direction {
 }
 
 type Position struct {
-	X, Y int
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Neighbour returns the adjacent position in direction d.
+func (p Position) Neighbour(d Direction) Position {
+	switch d {
+	case North:
+		return P(p.X, p.Y-1)
+	case East:
+		return P(p.X+1, p.Y)
+	case South:
+		return P(p.X, p.Y+1)
+	case West:
+		return P(p.X-1, p.Y)
+	default:
+		return p
+	}
 }
 
 func (p Position) Less(q Position) bool {
@@ -69,7 +158,8 @@ func (p Position) Neighbours() []Position {
 }
 
 type Size struct {
-	DX, DY int
+	DX int `json:"dx"`
+	DY int `json:"dy"`
 }
 
 type Rectangle struct {
@@ -85,6 +175,45 @@ func (r Rectangle) String() string {
 	return fmt.Sprintf("%d,%d+%dx%d", r.X, r.Y, r.DX, r.DY)
 }
 
+// DirectionFromRectangle returns the direction of the cell in from, which is
+// adjacent to pos.
+func DirectionFromRectangle(pos Position, from Rectangle) Direction {
+	for _, d := range []Direction{North, East, South, West} {
+		if from.Contains(pos.Neighbour(d)) {
+			return d
+		}
+	}
+	return None
+}
+
+func (r Rectangle) Contains(p Position) bool {
+	return p.X >= r.X && p.X < r.X+r.DX &&
+		p.Y >= r.Y && p.Y < r.Y+r.DY
+}
+
+// Grow returns r extended by n cells in every direction.
+func (r Rectangle) Grow(n int) Rectangle {
+	return R(P(r.X-n, r.Y-n), S(r.DX+2*n, r.DY+2*n))
+}
+
+// Intersects reports whether r and o share at least one cell.
+func (r Rectangle) Intersects(o Rectangle) bool {
+	return r.X < o.X+o.DX && o.X < r.X+r.DX &&
+		r.Y < o.Y+o.DY && o.Y < r.Y+r.DY
+}
+
+// Neighbours returns the positions outside of r which share an edge with r.
+func (r Rectangle) Neighbours() []Position {
+	var poss []Position
+	for x := r.X; x < r.X+r.DX; x++ {
+		poss = append(poss, P(x, r.Y-1), P(x, r.Y+r.DY))
+	}
+	for y := r.Y; y < r.Y+r.DY; y++ {
+		poss = append(poss, P(r.X-1, y), P(r.X+r.DX, y))
+	}
+	return poss
+}
+
 func (r Rectangle) Positions() []Position {
 	var poss []Position
 	for x := r.X; x < r.X+r.DX; x++ {
@@ -110,6 +239,7 @@ func New[T any](size Size) *Grid[T] {
 type Grid[T any] struct {
 	size    Size
 	objects map[Position]*Object[T]
+	sorted  []*Object[T] // cached result of Objects; nil if the grid has changed
 }
 
 func (g *Grid[T]) Size() Size {
@@ -121,7 +251,16 @@ func (g *Grid[T]) ContainsPosition(p Position) bool {
 		p.Y >= 0 && p.Y < g.size.DY
 }
 
+func (g *Grid[T]) ContainsRectangle(r Rectangle) bool {
+	return r.DX > 0 && r.DY > 0 &&
+		g.ContainsPosition(r.Position) &&
+		g.ContainsPosition(P(r.X+r.DX-1, r.Y+r.DY-1))
+}
+
 func (g *Grid[T]) CanAddRectangle(r Rectangle) bool {
+	if !g.ContainsRectangle(r) {
+		return false
+	}
 	for _, p := range r.Positions() {
 		if _, occ := g.objects[p]; occ {
 			return false
@@ -131,6 +270,9 @@ func (g *Grid[T]) CanAddRectangle(r Rectangle) bool {
 }
 
 func (g *Grid[T]) Add(t T, r Rectangle) error {
+	if !g.ContainsRectangle(r) {
+		return fmt.Errorf("rectangle %s is out of bounds", r)
+	}
 	if !g.CanAddRectangle(r) {
 		return fmt.Errorf("rectangle %s is already occupied", r)
 	}
@@ -138,25 +280,42 @@ func (g *Grid[T]) Add(t T, r Rectangle) error {
 	for _, p := range r.Positions() {
 		g.objects[p] = o
 	}
+	g.sorted = nil
 	return nil
 }
 
+// DeleteAt deletes the object occupying p from all of its positions.
 func (g *Grid[T]) DeleteAt(p Position) {
-	delete(g.objects, p)
+	o, ok := g.objects[p]
+	if !ok {
+		return
+	}
+	for _, op := range o.Positions() {
+		delete(g.objects, op)
+	}
+	g.sorted = nil
 }
 
 func (g *Grid[T]) ObjectAt(p Position) *Object[T] {
 	return g.objects[p]
 }
 
+// Objects returns each object once, sorted by position. The returned slice
+// is shared until the grid changes and must not be modified.
 func (g *Grid[T]) Objects() []*Object[T] {
-	poss := maps.Keys(g.objects)
-	sort.Slice(poss, func(i, j int) bool {
-		return poss[i].Less(poss[j])
-	})
-	objs := make([]*Object[T], len(poss))
-	for i, pos := range poss {
-		objs[i] = g.objects[pos]
+	if g.sorted != nil {
+		return g.sorted
+	}
+	objs := make([]*Object[T], 0, len(g.objects))
+	for pos, o := range g.objects {
+		// each object is collected at its top-left position only
+		if pos == o.Position {
+			objs = append(objs, o)
+		}
 	}
+	sort.Slice(objs, func(i, j int) bool {
+		return objs[i].Position.Less(objs[j].Position)
+	})
+	g.sorted = objs
 	return objs
 }
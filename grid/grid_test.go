@@ -0,0 +1,78 @@
+package grid
+
+import (
+	"testing"
+)
+
+func TestMultiTileObjects(t *testing.T) {
+	g := New[string](S(8, 8))
+	if err := g.Add("big", R(P(1, 1), S(3, 2))); err != nil {
+		t.Fatalf("add big: %v", err)
+	}
+	if err := g.Add("small", R(P(5, 5), S(1, 1))); err != nil {
+		t.Fatalf("add small: %v", err)
+	}
+	if err := g.Add("overlap", R(P(3, 2), S(2, 2))); err == nil {
+		t.Fatalf("add overlap: want error, have none")
+	}
+	if err := g.Add("outside", R(P(7, 7), S(2, 2))); err == nil {
+		t.Fatalf("add outside: want error, have none")
+	}
+
+	objs := g.Objects()
+	if len(objs) != 2 {
+		t.Fatalf("objects: want %d, have %d", 2, len(objs))
+	}
+	if o := g.ObjectAt(P(3, 2)); o == nil || o.Value != "big" {
+		t.Fatalf("object-at: want %q, have %v", "big", o)
+	}
+
+	g.DeleteAt(P(2, 2))
+	for _, p := range R(P(1, 1), S(3, 2)).Positions() {
+		if o := g.ObjectAt(p); o != nil {
+			t.Fatalf("object-at %s: want none, have %q", p, o.Value)
+		}
+	}
+	if len(g.Objects()) != 1 {
+		t.Fatalf("objects: want %d, have %d", 1, len(g.Objects()))
+	}
+}
+
+func TestRectangleNeighbours(t *testing.T) {
+	r := R(P(1, 1), S(2, 2))
+	ns := r.Neighbours()
+	if len(ns) != 8 {
+		t.Fatalf("neighbours: want %d, have %d", 8, len(ns))
+	}
+	for _, n := range ns {
+		if r.Contains(n) {
+			t.Fatalf("neighbour %s is inside %s", n, r)
+		}
+		if DirectionFromRectangle(n, r) == None {
+			t.Fatalf("neighbour %s is not adjacent to %s", n, r)
+		}
+	}
+}
+
+func TestDirectionRotation(t *testing.T) {
+	for _, d := range []Direction{North, East, South, West} {
+		if d.Left().Right() != d || d.Right().Right() != d.Opposite() {
+			t.Fatalf("rotation of %s is inconsistent", d)
+		}
+	}
+	if North.Left() != West || North.Right() != East {
+		t.Fatalf("north: want left %s and right %s, have %s and %s", West, East, North.Left(), North.Right())
+	}
+}
+
+func TestLayer(t *testing.T) {
+	l := NewLayer[int](S(3, 2))
+	l.Set(P(2, 1), 7)
+	l.Set(P(3, 1), 9) // out of bounds
+	if v := l.At(P(2, 1)); v != 7 {
+		t.Fatalf("at %s: want %d, have %d", P(2, 1), 7, v)
+	}
+	if v := l.At(P(1, 2)); v != 0 {
+		t.Fatalf("at %s: want %d, have %d", P(1, 2), 0, v)
+	}
+}
@@ -0,0 +1,41 @@
+package grid
+
+// Layer holds one value per cell. Cells, which have not been set, hold the
+// zero value of T.
+type Layer[T any] struct {
+	size  Size
+	cells []T
+}
+
+func NewLayer[T any](size Size) *Layer[T] {
+	return &Layer[T]{
+		size:  size,
+		cells: make([]T, size.DX*size.DY),
+	}
+}
+
+func (l *Layer[T]) Size() Size {
+	return l.size
+}
+
+func (l *Layer[T]) ContainsPosition(p Position) bool {
+	return p.X >= 0 && p.X < l.size.DX &&
+		p.Y >= 0 && p.Y < l.size.DY
+}
+
+// At returns the value at p, or the zero value, if p is out of bounds.
+func (l *Layer[T]) At(p Position) T {
+	if !l.ContainsPosition(p) {
+		var t T
+		return t
+	}
+	return l.cells[p.Y*l.size.DX+p.X]
+}
+
+// Set sets the value at p. Positions out of bounds are ignored.
+func (l *Layer[T]) Set(p Position, t T) {
+	if !l.ContainsPosition(p) {
+		return
+	}
+	l.cells[p.Y*l.size.DX+p.X] = t
+}
@@ -0,0 +1,212 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+var _ ProducerConsumer = &Inserter{}
+var _ StatusReporter = &Inserter{}
+
+type InserterPhase string
+
+const (
+	InserterPicking   InserterPhase = "picking"
+	InserterSwinging  InserterPhase = "swinging"
+	InserterDropping  InserterPhase = "dropping"
+	InserterReturning InserterPhase = "returning"
+)
+
+// NewInserter creates an inserter, which picks up items from its back and
+// drops them in front of it, facing dir. It picks up to itemsPerSwing items
+// and needs swingTicks ticks to swing to the front and the same to swing
+// back.
+func NewInserter(name string, dir grid.Direction, itemsPerSwing int, swingTicks int) *Inserter {
+	return &Inserter{
+		name:          name,
+		dir:           dir,
+		itemsPerSwing: itemsPerSwing,
+		swingTicks:    swingTicks,
+		hand:          NewQueue[Resource](),
+		phase:         InserterPicking,
+	}
+}
+
+type Inserter struct {
+	name          string
+	dir           grid.Direction
+	itemsPerSwing int
+	swingTicks    int
+	hand          *Queue[Resource]
+	phase         InserterPhase
+	progress      int  // ticks spent in the current swing
+	picked        bool // an item has been picked up since the last tick
+	waiting       int
+}
+
+func (ins *Inserter) Size() grid.Size {
+	return grid.S(1, 1)
+}
+
+func (ins *Inserter) Dir() grid.Direction {
+	return ins.dir
+}
+
+func (ins *Inserter) Phase() InserterPhase {
+	return ins.phase
+}
+
+func (ins *Inserter) Name() string {
+	return ins.name
+}
+
+func (ins *Inserter) Info() []string {
+	return []string{
+		fmt.Sprintf("Inserter: %s", ins.name),
+		fmt.Sprintf("Status: %s", ins.Status()),
+		fmt.Sprintf("Phase : %s", ins.phase),
+		fmt.Sprintf("Hand  : %d/%d", ins.hand.Len(), ins.itemsPerSwing),
+		fmt.Sprintf("Swing : %d ticks", ins.swingTicks),
+	}
+}
+
+// Tick advances the swing. While picking, the inserter starts to swing as
+// soon as its hand is full or no further item arrived.
+func (ins *Inserter) Tick() {
+	switch ins.phase {
+	case InserterPicking:
+		if ins.hand.Len() >= ins.itemsPerSwing || (ins.hand.Len() > 0 && !ins.picked) {
+			ins.phase, ins.progress = InserterSwinging, 0
+		}
+		ins.picked = false
+	case InserterSwinging:
+		ins.progress++
+		if ins.progress >= ins.swingTicks {
+			ins.phase = InserterDropping
+		}
+	case InserterDropping:
+		if ins.hand.Len() == 0 {
+			ins.phase, ins.progress = InserterReturning, 0
+			break
+		}
+		ins.waiting++
+	case InserterReturning:
+		ins.progress++
+		if ins.progress >= ins.swingTicks {
+			ins.phase = InserterPicking
+		}
+	}
+}
+
+func (ins *Inserter) Status() Status {
+	switch {
+	case ins.phase == InserterPicking && ins.hand.Len() == 0:
+		return Status{Kind: StatusIdle}
+	case ins.phase == InserterDropping && ins.waiting > 0:
+		return Status{Kind: StatusOutputBlocked}
+	default:
+		return Status{Kind: StatusWorking}
+	}
+}
+
+func (ins *Inserter) ConsumeAtPositions(r grid.Rectangle) []grid.Position {
+	return []grid.Position{r.Position}
+}
+
+func (ins *Inserter) ConsumeFrom(res Resource, dir grid.Direction) {
+	if !ins.CanConsumeFrom(res, dir) {
+		return
+	}
+	ins.hand.Enqueue(res)
+	ins.picked = true
+}
+
+func (ins *Inserter) CanConsumeFrom(res Resource, dir grid.Direction) bool {
+	return dir == ins.dir.Opposite() && ins.CanConsumeAny()
+}
+
+func (ins *Inserter) CanConsumeAny() bool {
+	return ins.phase == InserterPicking && ins.hand.Len() < ins.itemsPerSwing
+}
+
+func (ins *Inserter) ProduceAtPositions(r grid.Rectangle) []grid.Position {
+	return []grid.Position{r.Position.Neighbour(ins.dir)}
+}
+
+func (ins *Inserter) CanProduce() bool {
+	return ins.phase == InserterDropping && ins.hand.Len() > 0
+}
+
+func (ins *Inserter) Produce() (Resource, bool) {
+	if !ins.CanProduce() {
+		return None, false
+	}
+	res, _ := ins.hand.Dequeue()
+	ins.waiting = 0
+	return res, true
+}
+
+func (ins *Inserter) Resource() Resource {
+	res, ok := ins.hand.Peek()
+	if !ok {
+		return None
+	}
+	return res
+}
+
+type inserterJSON struct {
+	Name          string           `json:"name"`
+	Dir           grid.Direction   `json:"dir"`
+	ItemsPerSwing int              `json:"items_per_swing"`
+	SwingTicks    int              `json:"swing_ticks"`
+	Hand          *Queue[Resource] `json:"hand"`
+	Phase         InserterPhase    `json:"phase"`
+	Progress      int              `json:"progress"`
+	Picked        bool             `json:"picked"`
+	Waiting       int              `json:"waiting"`
+}
+
+func (ins *Inserter) Kind() ObjectKind {
+	return KindInserter
+}
+
+func (ins *Inserter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(inserterJSON{
+		Name:          ins.name,
+		Dir:           ins.dir,
+		ItemsPerSwing: ins.itemsPerSwing,
+		SwingTicks:    ins.swingTicks,
+		Hand:          ins.hand,
+		Phase:         ins.phase,
+		Progress:      ins.progress,
+		Picked:        ins.picked,
+		Waiting:       ins.waiting,
+	})
+}
+
+func (ins *Inserter) UnmarshalJSON(data []byte) error {
+	var ij inserterJSON
+	err := json.Unmarshal(data, &ij)
+	if err != nil {
+		return err
+	}
+	switch ij.Phase {
+	case InserterPicking, InserterSwinging, InserterDropping, InserterReturning:
+	default:
+		return fmt.Errorf("invalid phase %q", ij.Phase)
+	}
+	if ij.ItemsPerSwing <= 0 {
+		return fmt.Errorf("non-positive items per swing %d", ij.ItemsPerSwing)
+	}
+	*ins = *NewInserter(ij.Name, ij.Dir, ij.ItemsPerSwing, ij.SwingTicks)
+	if ij.Hand != nil {
+		ins.hand = ij.Hand
+	}
+	ins.phase = ij.Phase
+	ins.progress = ij.Progress
+	ins.picked = ij.Picked
+	ins.waiting = ij.Waiting
+	return nil
+}
@@ -0,0 +1,61 @@
+package minifac
+
+import (
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func TestExplicitIO(t *testing.T) {
+	tests := []struct {
+		direct   bool
+		conveyor bool // whether the conveyor next to the producer gets items
+	}{
+		{direct: false, conveyor: false},
+		{direct: true, conveyor: true},
+	}
+	for _, test := range tests {
+		u := NewUniverse(grid.S(3, 3))
+		if test.direct {
+			u.SetExplicitIO(false)
+		}
+		u.AddObject(NewIncarnationProducer("prod", Coal, NewRate(1, 1), 2), grid.P(0, 0))
+		u.AddObject(NewInserter("ins", grid.East, 1, 1), grid.P(1, 0))
+		u.AddObject(NewTrashbin("trash_ins"), grid.P(2, 0))
+		u.AddObject(NewConveyor("conv", grid.South, 2), grid.P(0, 1))
+		u.AddObject(NewTrashbin("trash_conv"), grid.P(0, 2))
+		repeat(u.Tick, 20)
+
+		if !test.direct && consumed(u, "trash_ins") == 0 {
+			t.Fatalf("direct %t: no items passed the inserter", test.direct)
+		}
+		if have := consumed(u, "trash_conv") > 0; have != test.conveyor {
+			t.Fatalf("direct %t: items passed the conveyor: want %t, have %t", test.direct, test.conveyor, have)
+		}
+	}
+}
+
+func TestInserterSwing(t *testing.T) {
+	ins := NewInserter("ins", grid.East, 2, 3)
+	ins.ConsumeFrom(Coal, grid.West)
+	ins.Tick()
+	ins.ConsumeFrom(Coal, grid.West)
+	if ins.CanConsumeFrom(Coal, grid.West) {
+		t.Fatalf("inserter takes more than %d items per swing", 2)
+	}
+	ins.Tick() // hand full, start swinging
+	repeat(ins.Tick, 2)
+	if ins.CanProduce() {
+		t.Fatalf("dropped before the swing finished")
+	}
+	ins.Tick()
+	for i := 0; i < 2; i++ {
+		if _, ok := ins.Produce(); !ok {
+			t.Fatalf("drop #%d: want item, have none", i+1)
+		}
+	}
+	ins.Tick()
+	if ins.Phase() != InserterReturning {
+		t.Fatalf("phase: want %s, have %s", InserterReturning, ins.Phase())
+	}
+}
@@ -0,0 +1,137 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mazzegi/minifac/grid"
+	"golang.org/x/exp/slices"
+)
+
+var _ Consumer = &Lab{}
+var _ StatusReporter = &Lab{}
+
+// NewLab creates a lab, which buffers up to capa items for the research.
+func NewLab(name string, capa int) *Lab {
+	return &Lab{
+		name:  name,
+		stock: NewStock(capa),
+	}
+}
+
+// Lab takes the resources the current technology needs and hands one item
+// per tick over to the research of the universe. All labs together take no
+// more than the outstanding cost. Items the current technology does not
+// need stay in the lab until a technology needs them.
+type Lab struct {
+	name  string
+	stock *Stock
+	// set by the universe each tick
+	demand      map[Resource]int // shared by all labs
+	researching bool
+}
+
+func (l *Lab) isMachine() {}
+
+func (l *Lab) Size() grid.Size {
+	return grid.S(1, 1)
+}
+
+func (l *Lab) Name() string {
+	return l.name
+}
+
+func (l *Lab) Amount(res Resource) int {
+	return l.stock.Amount(res)
+}
+
+func (l *Lab) Info() []string {
+	info := []string{
+		fmt.Sprintf("Lab: %s", l.name),
+		fmt.Sprintf("Content: %d/%d", l.stock.TotalAmount(), l.stock.capacity),
+	}
+	for _, res := range l.wants() {
+		info = append(info, fmt.Sprintf("Wants: %s", res))
+	}
+	for _, res := range l.stock.Resources() {
+		info = append(info, fmt.Sprintf("Stock: %s: %d", res, l.stock.Amount(res)))
+	}
+	return info
+}
+
+func (l *Lab) Tick() {}
+
+// wants returns the resources the labs still take, in sorted order.
+func (l *Lab) wants() []Resource {
+	var ress []Resource
+	for res, n := range l.demand {
+		if n > 0 {
+			ress = append(ress, res)
+		}
+	}
+	slices.Sort(ress)
+	return ress
+}
+
+func (l *Lab) Status() Status {
+	switch {
+	case l.researching:
+		return Status{Kind: StatusWorking}
+	case len(l.wants()) > 0:
+		return Status{Kind: StatusInputStarved, Missing: l.wants()}
+	default:
+		return Status{Kind: StatusIdle}
+	}
+}
+
+func (l *Lab) ConsumeAtPositions(r grid.Rectangle) []grid.Position {
+	return r.Positions()
+}
+
+func (l *Lab) ConsumeFrom(res Resource, dir grid.Direction) {
+	if !l.CanConsumeFrom(res, dir) {
+		return
+	}
+	l.stock.Add(res, 1)
+	l.demand[res]--
+}
+
+func (l *Lab) CanConsumeFrom(res Resource, dir grid.Direction) bool {
+	return l.demand[res] > 0 && l.stock.CanAdd(res, 1)
+}
+
+func (l *Lab) CanConsumeAny() bool {
+	return len(l.wants()) > 0 && l.stock.TotalAmount() < l.stock.capacity
+}
+
+type labJSON struct {
+	Name  string `json:"name"`
+	Stock *Stock `json:"stock"`
+}
+
+func (l *Lab) Kind() ObjectKind {
+	return KindLab
+}
+
+func (l *Lab) MarshalJSON() ([]byte, error) {
+	return json.Marshal(labJSON{
+		Name:  l.name,
+		Stock: l.stock,
+	})
+}
+
+func (l *Lab) UnmarshalJSON(data []byte) error {
+	var lj labJSON
+	err := json.Unmarshal(data, &lj)
+	if err != nil {
+		return err
+	}
+	if lj.Stock == nil {
+		return fmt.Errorf("missing stock")
+	}
+	*l = Lab{
+		name:  lj.Name,
+		stock: lj.Stock,
+	}
+	return nil
+}
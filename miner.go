@@ -0,0 +1,188 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+var _ Producer = &Miner{}
+var _ StatusReporter = &Miner{}
+
+// NewMiner creates a miner, which extracts rate items from the deposits
+// below it, as long as there is room in its stock.
+func NewMiner(name string, size grid.Size, rate Rate, stockCapa int) *Miner {
+	return &Miner{
+		name:  name,
+		size:  size,
+		rate:  rate,
+		stock: NewStock(stockCapa),
+	}
+}
+
+type Miner struct {
+	name         string
+	size         grid.Size
+	rate         Rate
+	stock        *Stock
+	lastProdTick int
+	currTick     int
+	// set when the miner is placed in a universe
+	deposits *grid.Layer[Deposit]
+	area     grid.Rectangle
+}
+
+func (m *Miner) isMachine() {}
+
+func (m *Miner) Size() grid.Size {
+	return m.size
+}
+
+func (m *Miner) Name() string {
+	return m.name
+}
+
+// Remaining returns the amount left in the deposits below the miner.
+func (m *Miner) Remaining() int {
+	if m.deposits == nil {
+		return 0
+	}
+	var n int
+	for _, p := range m.area.Positions() {
+		if d := m.deposits.At(p); !d.Empty() {
+			n += d.Amount
+		}
+	}
+	return n
+}
+
+func (m *Miner) Info() []string {
+	return []string{
+		fmt.Sprintf("Miner: %s", m.name),
+		fmt.Sprintf("Rate     : %d/%d", m.rate.count, m.rate.perTicks),
+		fmt.Sprintf("Stock    : %d", m.stock.TotalAmount()),
+		fmt.Sprintf("Remaining: %d", m.Remaining()),
+		fmt.Sprintf("Status   : %s", m.Status()),
+	}
+}
+
+func (m *Miner) Tick() {
+	m.currTick++
+	cnt := m.rate.Count(m.currTick - m.lastProdTick)
+	if cnt == 0 {
+		return
+	}
+	m.lastProdTick = m.currTick
+	for i := 0; i < cnt; i++ {
+		if !m.extract() {
+			return
+		}
+	}
+}
+
+// extract moves one item from the first deposit below the miner, which is
+// not depleted, to the stock.
+func (m *Miner) extract() bool {
+	if m.deposits == nil {
+		return false
+	}
+	for _, p := range m.area.Positions() {
+		d := m.deposits.At(p)
+		if d.Empty() {
+			continue
+		}
+		if !m.stock.CanAdd(d.Resource, 1) {
+			return false
+		}
+		m.stock.Add(d.Resource, 1)
+		d.Amount--
+		if d.Amount == 0 {
+			d = Deposit{}
+		}
+		m.deposits.Set(p, d)
+		return true
+	}
+	return false
+}
+
+// Status reports a depleted miner with an empty stock as idle.
+func (m *Miner) Status() Status {
+	switch {
+	case m.stock.TotalAmount() >= m.stock.capacity:
+		return Status{Kind: StatusOutputBlocked}
+	case m.stock.TotalAmount() == 0 && m.Remaining() == 0:
+		return Status{Kind: StatusIdle}
+	default:
+		return Status{Kind: StatusWorking}
+	}
+}
+
+func (m *Miner) ProduceAtPositions(r grid.Rectangle) []grid.Position {
+	return r.Neighbours()
+}
+
+func (m *Miner) CanProduce() bool {
+	return m.stock.TotalAmount() > 0
+}
+
+func (m *Miner) Produce() (Resource, bool) {
+	res := m.Resource()
+	if res == None {
+		return None, false
+	}
+	m.stock.Take(res, 1)
+	return res, true
+}
+
+func (m *Miner) Resource() Resource {
+	ress := m.stock.Resources()
+	if len(ress) == 0 {
+		return None
+	}
+	return ress[0]
+}
+
+type minerJSON struct {
+	Name         string    `json:"name"`
+	Size         grid.Size `json:"size"`
+	Rate         Rate      `json:"rate"`
+	Stock        *Stock    `json:"stock"`
+	LastProdTick int       `json:"last_prod_tick"`
+	CurrTick     int       `json:"curr_tick"`
+}
+
+func (m *Miner) Kind() ObjectKind {
+	return KindMiner
+}
+
+func (m *Miner) MarshalJSON() ([]byte, error) {
+	return json.Marshal(minerJSON{
+		Name:         m.name,
+		Size:         m.size,
+		Rate:         m.rate,
+		Stock:        m.stock,
+		LastProdTick: m.lastProdTick,
+		CurrTick:     m.currTick,
+	})
+}
+
+func (m *Miner) UnmarshalJSON(data []byte) error {
+	var mj minerJSON
+	err := json.Unmarshal(data, &mj)
+	if err != nil {
+		return err
+	}
+	if mj.Stock == nil {
+		return fmt.Errorf("missing stock")
+	}
+	*m = Miner{
+		name:         mj.Name,
+		size:         mj.Size,
+		rate:         mj.Rate,
+		stock:        mj.Stock,
+		lastProdTick: mj.LastProdTick,
+		currTick:     mj.CurrTick,
+	}
+	return nil
+}
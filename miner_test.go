@@ -0,0 +1,56 @@
+package minifac
+
+import (
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func TestMinerPlacement(t *testing.T) {
+	u := NewUniverse(grid.S(3, 1))
+	u.SetDeposit(grid.P(2, 0), Deposit{Resource: Coal, Amount: 10})
+	if err := u.AddObject(NewMiner("miner", grid.S(1, 1), NewRate(1, 1), 2), grid.P(0, 0)); err == nil {
+		t.Fatalf("add miner off a deposit: want error, have none")
+	}
+	if err := u.AddObject(NewMiner("miner", grid.S(1, 1), NewRate(1, 1), 2), grid.P(2, 0)); err != nil {
+		t.Fatalf("add miner on a deposit: %v", err)
+	}
+}
+
+func TestMinerDepletesDeposit(t *testing.T) {
+	u := NewUniverse(grid.S(2, 2))
+	u.SetExplicitIO(false)
+	u.SetDeposit(grid.P(0, 0), Deposit{Resource: Coal, Amount: 5})
+	u.SetDeposit(grid.P(0, 1), Deposit{Resource: Coal, Amount: 3})
+	m := NewMiner("miner", grid.S(1, 2), NewRate(1, 1), 2)
+	u.AddObject(m, grid.P(0, 0))
+	u.AddObject(NewFinalizer("fin", Coal), grid.P(1, 0))
+	repeat(u.Tick, 20)
+	if n := consumed(u, "fin"); n != 8 {
+		t.Fatalf("consumed: want %d, have %d", 8, n)
+	}
+	if _, ok := u.DepositAt(grid.P(0, 0)); ok {
+		t.Fatalf("deposit at %s: want depleted", grid.P(0, 0))
+	}
+	if st := m.Status().Kind; st != StatusIdle {
+		t.Fatalf("status: want %s, have %s", StatusIdle, st)
+	}
+}
+
+func TestDepletedMinerDeliversStock(t *testing.T) {
+	u := NewUniverse(grid.S(1, 1))
+	u.SetDeposit(grid.P(0, 0), Deposit{Resource: Coal, Amount: 2})
+	m := NewMiner("miner", grid.S(1, 1), NewRate(1, 1), 3)
+	u.AddObject(m, grid.P(0, 0))
+	repeat(u.Tick, 5)
+	if n := m.Remaining(); n != 0 {
+		t.Fatalf("remaining: want %d, have %d", 0, n)
+	}
+	if st := m.Status().Kind; st != StatusWorking {
+		t.Fatalf("status with stock: want %s, have %s", StatusWorking, st)
+	}
+	repeat(func() { m.Produce() }, 2)
+	if st := m.Status().Kind; st != StatusIdle {
+		t.Fatalf("status without stock: want %s, have %s", StatusIdle, st)
+	}
+}
@@ -1,6 +1,7 @@
 package minifac
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/mazzegi/minifac/grid"
@@ -47,3 +48,29 @@ func (c *Obstacle) Info() []string {
 		fmt.Sprintf("Obstacle: %s", c.name),
 	}
 }
+
+type obstacleJSON struct {
+	Name string       `json:"name"`
+	Type ObstacleType `json:"type"`
+}
+
+func (c *Obstacle) Kind() ObjectKind {
+	return KindObstacle
+}
+
+func (c *Obstacle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obstacleJSON{
+		Name: c.name,
+		Type: c.typ,
+	})
+}
+
+func (c *Obstacle) UnmarshalJSON(data []byte) error {
+	var oj obstacleJSON
+	err := json.Unmarshal(data, &oj)
+	if err != nil {
+		return err
+	}
+	*c = *NewObstacle(oj.Name, oj.Type)
+	return nil
+}
@@ -0,0 +1,239 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+// FormatVersion is the version of the format written by Universe.Save.
+const FormatVersion = 2
+
+type ObjectKind string
+
+const (
+	KindConveyor            ObjectKind = "conveyor"
+	KindAssembler           ObjectKind = "assembler"
+	KindIncarnationProducer ObjectKind = "incarnation_producer"
+	KindFinalizer           ObjectKind = "finalizer"
+	KindTrashbin            ObjectKind = "trashbin"
+	KindObstacle            ObjectKind = "obstacle"
+	KindGenerator           ObjectKind = "generator"
+	KindPowerPole           ObjectKind = "power_pole"
+	KindSplitter            ObjectKind = "splitter"
+	KindSorter              ObjectKind = "sorter"
+	KindTunnelEntrance      ObjectKind = "tunnel_entrance"
+	KindTunnelExit          ObjectKind = "tunnel_exit"
+	KindInserter            ObjectKind = "inserter"
+	KindChest               ObjectKind = "chest"
+	KindMiner               ObjectKind = "miner"
+	KindLab                 ObjectKind = "lab"
+)
+
+// persistent is implemented by all objects, which can be saved including
+// their internal state.
+type persistent interface {
+	Object
+	json.Marshaler
+	json.Unmarshaler
+	Kind() ObjectKind
+}
+
+var persistentKinds = map[ObjectKind]func() persistent{
+	KindConveyor:            func() persistent { return &Conveyor{} },
+	KindAssembler:           func() persistent { return &Assembler{} },
+	KindIncarnationProducer: func() persistent { return &IncarnationProducer{} },
+	KindFinalizer:           func() persistent { return &Finalizer{} },
+	KindTrashbin:            func() persistent { return &Trashbin{} },
+	KindObstacle:            func() persistent { return &Obstacle{} },
+	KindGenerator:           func() persistent { return &Generator{} },
+	KindPowerPole:           func() persistent { return &PowerPole{} },
+	KindSplitter:            func() persistent { return &Splitter{} },
+	KindSorter:              func() persistent { return &Sorter{} },
+	KindTunnelEntrance:      func() persistent { return &TunnelEntrance{} },
+	KindTunnelExit:          func() persistent { return &TunnelExit{} },
+	KindInserter:            func() persistent { return &Inserter{} },
+	KindChest:               func() persistent { return &Chest{} },
+	KindMiner:               func() persistent { return &Miner{} },
+	KindLab:                 func() persistent { return &Lab{} },
+}
+
+type universeJSON struct {
+	Version    int                 `json:"version"`
+	Size       grid.Size           `json:"size"`
+	ExplicitIO *bool               `json:"explicit_io,omitempty"`
+	Tick       int                 `json:"tick,omitempty"`
+	Delivered  map[Resource]int    `json:"delivered,omitempty"`
+	Terrain    []PositionedTerrain `json:"terrain,omitempty"`
+	Deposits   []PositionedDeposit `json:"deposits,omitempty"`
+	Research   *Research           `json:"research,omitempty"`
+	Objects    []objectJSON        `json:"objects"`
+}
+
+type objectJSON struct {
+	Kind     ObjectKind       `json:"kind"`
+	Position grid.Position    `json:"position"`
+	State    json.RawMessage  `json:"state"`
+	Stats    *objectStatsJSON `json:"stats,omitempty"`
+	// placed before research was enabled
+	Preset bool `json:"preset,omitempty"`
+}
+
+// Save writes the universe including the state of all objects as JSON.
+func (u *Universe) Save(w io.Writer) error {
+	uj := universeJSON{
+		Version:    FormatVersion,
+		Size:       u.Size(),
+		ExplicitIO: &u.explicitIO,
+		Tick:       u.tick,
+		Delivered:  u.delivered,
+		Terrain:    u.AllTerrain(),
+		Deposits:   u.AllDeposits(),
+		Research:   u.research,
+		Objects:    []objectJSON{},
+	}
+	for _, obj := range u.AllObjects() {
+		p, ok := obj.Value.(persistent)
+		if !ok {
+			return fmt.Errorf("object %q at %s of type %T cannot be saved", obj.Value.Name(), obj.Position, obj.Value)
+		}
+		state, err := p.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf("marshal object %q at %s: %w", p.Name(), obj.Position, err)
+		}
+		oj := objectJSON{
+			Kind:     p.Kind(),
+			Position: obj.Position,
+			State:    state,
+		}
+		oj.Preset = u.preset[obj.Value]
+		if s, ok := u.stats[obj.Value]; ok {
+			oj.Stats = &objectStatsJSON{Total: s.Total, Ticks: s.Ticks}
+		}
+		uj.Objects = append(uj.Objects, oj)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(uj)
+}
+
+// Load reads a universe written by Universe.Save.
+func Load(r io.Reader) (*Universe, error) {
+	var uj universeJSON
+	err := json.NewDecoder(r).Decode(&uj)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	switch uj.Version {
+	case 1:
+		err := migrateV1(&uj)
+		if err != nil {
+			return nil, fmt.Errorf("migrate version 1: %w", err)
+		}
+	case FormatVersion:
+	default:
+		return nil, fmt.Errorf("unsupported format version %d (want %d)", uj.Version, FormatVersion)
+	}
+	u := NewUniverse(uj.Size)
+	// saves without the setting were written when direct I/O was the default
+	u.SetExplicitIO(uj.ExplicitIO != nil && *uj.ExplicitIO)
+	u.SetResearch(uj.Research)
+	u.tick = uj.Tick
+	for res, n := range uj.Delivered {
+		u.delivered[res] = n
+	}
+	for _, pt := range uj.Terrain {
+		err := u.SetTerrain(pt.Position, pt.Terrain)
+		if err != nil {
+			return nil, fmt.Errorf("terrain: %w", err)
+		}
+	}
+	for _, pd := range uj.Deposits {
+		err := u.SetDeposit(pd.Position, pd.Deposit)
+		if err != nil {
+			return nil, fmt.Errorf("deposit: %w", err)
+		}
+	}
+	for _, oj := range uj.Objects {
+		newFnc, ok := persistentKinds[oj.Kind]
+		if !ok {
+			return nil, fmt.Errorf("unknown object kind %q at %s", oj.Kind, oj.Position)
+		}
+		obj := newFnc()
+		err := obj.UnmarshalJSON(oj.State)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal %s at %s: %w", oj.Kind, oj.Position, err)
+		}
+		if oj.Preset {
+			u.preset[obj] = true
+		}
+		err = u.checkPlacement(obj, grid.R(oj.Position, obj.Size()), true)
+		if err == nil {
+			err = u.place(obj, oj.Position)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("add %s at %s: %w", oj.Kind, oj.Position, err)
+		}
+		if oj.Stats != nil {
+			u.stats[obj] = &ObjectStats{Total: oj.Stats.Total, Ticks: oj.Stats.Ticks}
+		}
+	}
+	return u, nil
+}
+
+// migrateV1 upgrades the receipts of assemblers written in version 1, which
+// have a single output instead of a list of outputs.
+func migrateV1(uj *universeJSON) error {
+	for i, oj := range uj.Objects {
+		if oj.Kind != KindAssembler {
+			continue
+		}
+		var state map[string]json.RawMessage
+		err := json.Unmarshal(oj.State, &state)
+		if err != nil {
+			return fmt.Errorf("%s at %s: %w", oj.Kind, oj.Position, err)
+		}
+		var rec map[string]json.RawMessage
+		err = json.Unmarshal(state["receipt"], &rec)
+		if err != nil {
+			return fmt.Errorf("receipt of %s at %s: %w", oj.Kind, oj.Position, err)
+		}
+		var output Resource
+		err = json.Unmarshal(rec["output"], &output)
+		if err != nil {
+			return fmt.Errorf("output of %s at %s: %w", oj.Kind, oj.Position, err)
+		}
+		delete(rec, "output")
+		rec["outputs"], err = json.Marshal([]Amount{{Resource: output, Count: 1}})
+		if err != nil {
+			return err
+		}
+		state["receipt"], err = json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		uj.Objects[i].State, err = json.Marshal(state)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile reads a universe from a file. Files with extension .json are
+// expected to be written by Universe.Save, all others to be text maps.
+func LoadFile(path string) (*Universe, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", path, err)
+	}
+	defer f.Close()
+	if filepath.Ext(path) == ".json" {
+		return Load(f)
+	}
+	return ParseTextMap(f)
+}
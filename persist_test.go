@@ -0,0 +1,208 @@
+package minifac
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func setupAllKinds() *Universe {
+	u := NewUniverse(grid.S(12, 6))
+	u.AddObject(NewObstacle("wall", ObstacleWall), grid.P(0, 0))
+	u.AddObject(NewIncarnationProducer("prod_coal", Coal, NewRate(1, 2), 2), grid.P(1, 2))
+	u.AddObject(NewIncarnationProducer("prod_iron", Iron, NewRate(1, 3), 2), grid.P(2, 4))
+	u.AddObject(NewConveyor("conv_1", grid.East, 1), grid.P(2, 2))
+	u.AddObject(NewConveyor("conv_2", grid.East, 2), grid.P(3, 2))
+	u.AddObject(NewSizedAssembler("ass_steel", grid.S(2, 2), ReceiptSteel(), 5, 5), grid.P(4, 2))
+	u.AddObject(NewConveyor("conv_3", grid.North, 1), grid.P(2, 3))
+	u.AddObject(NewConveyor("conv_4", grid.East, 1), grid.P(6, 2))
+	u.AddObject(NewFinalizer("fin_steel", Steel), grid.P(7, 2))
+	u.AddObject(NewTrashbin("trash"), grid.P(6, 3))
+	u.AddObject(NewGenerator("gen", 10, 20, 5), grid.P(9, 0))
+	u.AddObject(NewPowerPole("pole", 2), grid.P(10, 1))
+	u.AddObject(NewTunnelEntrance("tunnel", grid.East, 3, 3), grid.P(8, 5))
+	u.AddObject(NewObstacle("wall_2", ObstacleWall), grid.P(9, 5))
+	u.AddObject(NewTunnelExit("tunnel_exit", grid.East), grid.P(10, 5))
+	u.AddObject(NewInserter("ins", grid.South, 2, 2), grid.P(11, 3))
+	u.AddObject(NewChest("chest", 10, Coal), grid.P(0, 3))
+	u.SetDeposit(grid.P(0, 5), Deposit{Resource: Coal, Amount: 30})
+	u.SetDeposit(grid.P(1, 5), Deposit{Resource: IronOre, Amount: 20})
+	u.AddObject(NewMiner("miner", grid.S(1, 1), NewRate(1, 2), 2), grid.P(0, 5))
+	u.SetTerrain(grid.P(11, 0), TerrainWater)
+	u.SetTerrain(grid.P(11, 1), TerrainRock)
+	u.AddObject(NewLab("lab", 5), grid.P(4, 5))
+	r, _ := NewResearch(DefaultTechTree())
+	u.SetResearch(r)
+	return u
+}
+
+func saveString(t *testing.T, u *Universe) string {
+	buf := &bytes.Buffer{}
+	err := u.Save(buf)
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	return buf.String()
+}
+
+func TestSaveLoad(t *testing.T) {
+	u := setupAllKinds()
+	repeat(u.Tick, 17)
+	saved := saveString(t, u)
+
+	lu, err := Load(bytes.NewBufferString(saved))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if have := saveString(t, lu); have != saved {
+		t.Fatalf("save after load differs:\nwant:\n%s\nhave:\n%s", saved, have)
+	}
+
+	// both universes must evolve the same way
+	repeat(u.Tick, 23)
+	repeat(lu.Tick, 23)
+	if want, have := saveString(t, u), saveString(t, lu); have != want {
+		t.Fatalf("save after ticks differs:\nwant:\n%s\nhave:\n%s", want, have)
+	}
+}
+
+func TestSaveLoadStats(t *testing.T) {
+	u := NewUniverse(grid.S(2, 1))
+	u.SetExplicitIO(false)
+	u.AddObject(NewIncarnationProducer("prod", Coal, NewRate(1, 1), 2), grid.P(0, 0))
+	u.AddObject(NewFinalizer("fin", Coal), grid.P(1, 0))
+	repeat(u.Tick, 10)
+
+	lu, err := Load(bytes.NewBufferString(saveString(t, u)))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if have := lu.Ticks(); have != u.Ticks() {
+		t.Fatalf("ticks: want %d, have %d", u.Ticks(), have)
+	}
+	if have := lu.Delivered(Coal); have == 0 || have != u.Delivered(Coal) {
+		t.Fatalf("delivered: want %d, have %d", u.Delivered(Coal), have)
+	}
+	if have := consumed(lu, "fin"); have != consumed(u, "fin") {
+		t.Fatalf("consumed: want %d, have %d", consumed(u, "fin"), have)
+	}
+}
+
+func TestLoadInvalidVersion(t *testing.T) {
+	_, err := Load(bytes.NewBufferString(`{"version": 0, "size": {"dx": 2, "dy": 2}, "objects": []}`))
+	if err == nil {
+		t.Fatalf("load: want error, have none")
+	}
+}
+
+func TestLoadAssemblerBeforeSpeed(t *testing.T) {
+	var a Assembler
+	err := a.UnmarshalJSON([]byte(`{
+		"name": "ass",
+		"size": {"dx": 1, "dy": 1},
+		"receipt": {"input": {"ironore": 1}, "outputs": [{"resource": "iron", "count": 1}], "production_time": 3},
+		"in_stocks": {"ironore": {"capacity": 5, "resources": {}}},
+		"out_stock": {"capacity": 5, "resources": {}},
+		"last_prod_tick": 7,
+		"curr_tick": 9,
+		"producing": true
+	}`))
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	a.Tick()
+	if n := a.outStock.Amount(Iron); n != 1 {
+		t.Fatalf("iron after resuming: want %d, have %d", 1, n)
+	}
+	a.SetReceipt(ReceiptIron())
+	if !a.CanConsumeFrom(Coal, grid.West) {
+		t.Fatalf("consume %s after changing the receipt: want true, have false", Coal)
+	}
+}
+
+func TestLoadConveyorBeforeSpeed(t *testing.T) {
+	var c Conveyor
+	err := c.UnmarshalJSON([]byte(`{"name": "conv", "dir": "east", "capacity": 2, "buffer": ["coal"], "waiting": 0}`))
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if res, ok := c.Produce(); !ok || res != Coal {
+		t.Fatalf("produce: want %s, have %s (%t)", Coal, res, ok)
+	}
+}
+
+func TestLoadVersion1(t *testing.T) {
+	u, err := Load(bytes.NewBufferString(`{
+		"version": 1,
+		"size": {"dx": 3, "dy": 1},
+		"objects": [
+			{"kind": "conveyor", "position": {"x": 0, "y": 0}, "state": {"name": "conv", "dir": "east", "capacity": 1, "buffer": ["ironore"], "waiting": 0}},
+			{"kind": "assembler", "position": {"x": 1, "y": 0}, "state": {
+				"name": "ass",
+				"size": {"dx": 1, "dy": 1},
+				"receipt": {"input": {"ironore": 1}, "output": "iron", "production_time": 1},
+				"in_stocks": {"ironore": {"capacity": 5, "resources": {}}},
+				"out_stock": {"capacity": 5, "resources": {}},
+				"last_prod_tick": 0,
+				"curr_tick": 0,
+				"producing": false
+			}},
+			{"kind": "finalizer", "position": {"x": 2, "y": 0}, "state": {"name": "fin", "resource": "iron"}}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if u.ExplicitIO() {
+		t.Fatalf("explicit io: want %t, have %t", false, true)
+	}
+	obj, _ := u.ObjectAt(grid.P(1, 0))
+	outs := obj.Value.(*Assembler).Receipt().Outputs
+	if len(outs) != 1 || outs[0] != (Amount{Resource: Iron, Count: 1}) {
+		t.Fatalf("outputs: want %v, have %v", []Amount{{Resource: Iron, Count: 1}}, outs)
+	}
+	repeat(u.Tick, 5)
+	if n := u.Delivered(Iron); n != 1 {
+		t.Fatalf("delivered: want %d, have %d", 1, n)
+	}
+}
+
+func TestLoadChecksPlacement(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(u *Universe)
+		valid bool
+	}{
+		{name: "conveyor on water", setup: func(u *Universe) {
+			u.SetTerrain(grid.P(0, 0), TerrainWater)
+			u.place(NewConveyor("conv", grid.East, 1), grid.P(0, 0))
+		}},
+		{name: "locked object", setup: func(u *Universe) {
+			r, _ := NewResearch(DefaultTechTree())
+			u.SetResearch(r)
+			u.place(NewChest("chest", 1, None), grid.P(0, 0))
+		}},
+		{name: "object placed before research", setup: func(u *Universe) {
+			u.place(NewChest("chest", 1, None), grid.P(0, 0))
+			r, _ := NewResearch(DefaultTechTree())
+			u.SetResearch(r)
+		}, valid: true},
+		{name: "miner on depleted deposit", setup: func(u *Universe) {
+			u.SetDeposit(grid.P(0, 0), Deposit{Resource: Coal, Amount: 1})
+			u.AddObject(NewMiner("miner", grid.S(1, 1), NewRate(1, 1), 2), grid.P(0, 0))
+			repeat(u.Tick, 3)
+		}, valid: true},
+	}
+	for _, test := range tests {
+		u := NewUniverse(grid.S(2, 1))
+		test.setup(u)
+		_, err := Load(bytes.NewBufferString(saveString(t, u)))
+		if test.valid && err != nil {
+			t.Fatalf("%s: load: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Fatalf("%s: load: want error, have none", test.name)
+		}
+	}
+}
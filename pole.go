@@ -0,0 +1,79 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+var _ Object = &PowerPole{}
+
+// NewPowerPole creates a pole, which connects all objects within radius
+// tiles to a power network.
+func NewPowerPole(name string, radius int) *PowerPole {
+	return &PowerPole{
+		name:   name,
+		radius: radius,
+	}
+}
+
+type PowerPole struct {
+	name   string
+	radius int
+}
+
+func (p *PowerPole) Size() grid.Size {
+	return grid.S(1, 1)
+}
+
+func (p *PowerPole) Radius() int {
+	return p.radius
+}
+
+func (p *PowerPole) Tick() {
+
+}
+
+func (p *PowerPole) Name() string {
+	return p.name
+}
+
+func (p *PowerPole) Info() []string {
+	return []string{
+		fmt.Sprintf("Power pole: %s", p.name),
+		fmt.Sprintf("Radius: %d", p.radius),
+	}
+}
+
+type powerPoleJSON struct {
+	Name   string `json:"name"`
+	Radius int    `json:"radius"`
+}
+
+func (p *PowerPole) Kind() ObjectKind {
+	return KindPowerPole
+}
+
+func (p *PowerPole) MarshalJSON() ([]byte, error) {
+	return json.Marshal(powerPoleJSON{
+		Name:   p.name,
+		Radius: p.radius,
+	})
+}
+
+func (p *PowerPole) UnmarshalJSON(data []byte) error {
+	var pj powerPoleJSON
+	err := json.Unmarshal(data, &pj)
+	if err != nil {
+		return err
+	}
+	if pj.Radius < 0 {
+		return fmt.Errorf("negative radius %d", pj.Radius)
+	}
+	*p = PowerPole{
+		name:   pj.Name,
+		radius: pj.Radius,
+	}
+	return nil
+}
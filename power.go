@@ -0,0 +1,196 @@
+package minifac
+
+import (
+	"fmt"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+// PowerConsumer is implemented by objects, which need power to work.
+type PowerConsumer interface {
+	// PowerDemand returns the power needed in the coming tick
+	PowerDemand() float64
+	// SetPowerSatisfaction sets the share of the demand, which is covered
+	// in the coming tick
+	SetPowerSatisfaction(f float64)
+}
+
+// PowerSupplier is implemented by objects, which feed power into a network.
+type PowerSupplier interface {
+	// PowerSupply returns the power, which can be provided in the coming tick
+	PowerSupply() float64
+	// SetPowerLoad sets the share of the supply, which is used in the coming
+	// tick
+	SetPowerLoad(f float64)
+}
+
+// PowerNetwork is a set of connected power poles together with the
+// suppliers and consumers in their range.
+type PowerNetwork struct {
+	ID        int
+	Poles     []string
+	Suppliers []string
+	Consumers []string
+	Supply    float64
+	Demand    float64
+
+	suppliers []PowerSupplier
+	consumers []PowerConsumer
+}
+
+// Satisfaction returns the share of the demand, which is covered by the
+// supply.
+func (n *PowerNetwork) Satisfaction() float64 {
+	if n.Demand <= 0 {
+		return 1
+	}
+	return Min(1, n.Supply/n.Demand)
+}
+
+// Load returns the share of the supply, which is used by the consumers.
+func (n *PowerNetwork) Load() float64 {
+	if n.Supply <= 0 {
+		return 0
+	}
+	return Min(1, n.Demand/n.Supply)
+}
+
+func (n *PowerNetwork) Info() []string {
+	return []string{
+		fmt.Sprintf("Network: %d (%d poles)", n.ID, len(n.Poles)),
+		fmt.Sprintf("Supply: %.1f", n.Supply),
+		fmt.Sprintf("Demand: %.1f", n.Demand),
+		fmt.Sprintf("Satisfaction: %.0f%%", 100*n.Satisfaction()),
+	}
+}
+
+// PowerNetworks returns the power networks as of the last tick.
+func (u *Universe) PowerNetworks() []*PowerNetwork {
+	u.buildPowerNetworks()
+	return u.networks
+}
+
+// PowerNetworkAt returns the network, which the object at p belongs to.
+func (u *Universe) PowerNetworkAt(p grid.Position) (*PowerNetwork, bool) {
+	u.buildPowerNetworks()
+	obj := u.grid.ObjectAt(p)
+	if obj == nil {
+		return nil, false
+	}
+	n, ok := u.networkOf[obj.Value]
+	return n, ok
+}
+
+// buildPowerNetworks rebuilds the networks, if objects have been added or
+// deleted since the last build. Poles are connected, if their ranges
+// overlap. Other objects belong to the network of the first pole, whose
+// range covers one of their tiles.
+func (u *Universe) buildPowerNetworks() {
+	if !u.powerDirty {
+		return
+	}
+	u.powerDirty = false
+	u.networks = nil
+	u.networkOf = map[Object]*PowerNetwork{}
+
+	var poles []*grid.Object[Object]
+	var ranges []grid.Rectangle
+	for _, obj := range u.grid.Objects() {
+		if pole, ok := obj.Value.(*PowerPole); ok {
+			poles = append(poles, obj)
+			ranges = append(ranges, obj.Rectangle.Grow(pole.Radius()))
+		}
+	}
+	if len(poles) == 0 {
+		return
+	}
+
+	// union-find over the poles
+	parent := make([]int, len(poles))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range poles {
+		for j := i + 1; j < len(poles); j++ {
+			if ranges[i].Intersects(ranges[j]) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	poleNetworks := make([]*PowerNetwork, len(poles))
+	for i, pole := range poles {
+		root := find(i)
+		if poleNetworks[root] == nil {
+			poleNetworks[root] = &PowerNetwork{ID: len(u.networks) + 1}
+			u.networks = append(u.networks, poleNetworks[root])
+		}
+		n := poleNetworks[root]
+		poleNetworks[i] = n
+		n.Poles = append(n.Poles, pole.Value.Name())
+		u.networkOf[pole.Value] = n
+	}
+
+	for _, obj := range u.grid.Objects() {
+		sup, isSupplier := obj.Value.(PowerSupplier)
+		con, isConsumer := obj.Value.(PowerConsumer)
+		if !isSupplier && !isConsumer {
+			continue
+		}
+		for i, r := range ranges {
+			if !r.Intersects(obj.Rectangle) {
+				continue
+			}
+			n := poleNetworks[i]
+			u.networkOf[obj.Value] = n
+			if isSupplier {
+				n.Suppliers = append(n.Suppliers, obj.Value.Name())
+				n.suppliers = append(n.suppliers, sup)
+			}
+			if isConsumer {
+				n.Consumers = append(n.Consumers, obj.Value.Name())
+				n.consumers = append(n.consumers, con)
+			}
+			break
+		}
+	}
+}
+
+// updatePower balances supply and demand of all networks for the coming
+// tick. Consumers outside of any network get no power at all.
+func (u *Universe) updatePower(objs []*grid.Object[Object]) {
+	u.buildPowerNetworks()
+	for _, obj := range objs {
+		if _, ok := u.networkOf[obj.Value]; ok {
+			continue
+		}
+		if con, ok := obj.Value.(PowerConsumer); ok {
+			con.SetPowerSatisfaction(0)
+		}
+		if sup, ok := obj.Value.(PowerSupplier); ok {
+			sup.SetPowerLoad(0)
+		}
+	}
+	for _, n := range u.networks {
+		n.Supply, n.Demand = 0, 0
+		for _, sup := range n.suppliers {
+			n.Supply += sup.PowerSupply()
+		}
+		for _, con := range n.consumers {
+			n.Demand += con.PowerDemand()
+		}
+		for _, sup := range n.suppliers {
+			sup.SetPowerLoad(n.Load())
+		}
+		for _, con := range n.consumers {
+			con.SetPowerSatisfaction(n.Satisfaction())
+		}
+	}
+}
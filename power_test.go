@@ -0,0 +1,83 @@
+package minifac
+
+import (
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func TestPowerNetworks(t *testing.T) {
+	u := NewUniverse(grid.S(20, 5))
+	u.AddObject(NewPowerPole("pole_1", 2), grid.P(2, 2))
+	u.AddObject(NewPowerPole("pole_2", 2), grid.P(6, 2))
+	u.AddObject(NewPowerPole("pole_3", 2), grid.P(15, 2))
+	u.AddObject(NewGenerator("gen", 10, 20, 5), grid.P(0, 2))
+	ass := NewAssembler("ass", ReceiptIron(), 5, 5)
+	ass.SetPowerUsage(5)
+	u.AddObject(ass, grid.P(8, 2))
+
+	nets := u.PowerNetworks()
+	if len(nets) != 2 {
+		t.Fatalf("networks: want %d, have %d", 2, len(nets))
+	}
+	n, ok := u.PowerNetworkAt(grid.P(8, 2))
+	if !ok || n != nets[0] {
+		t.Fatalf("network of assembler: want %v, have %v", nets[0], n)
+	}
+	if len(n.Suppliers) != 1 || len(n.Consumers) != 1 {
+		t.Fatalf("members: want 1 supplier and 1 consumer, have %v and %v", n.Suppliers, n.Consumers)
+	}
+
+	u.DeleteAt(grid.P(6, 2))
+	if _, ok := u.PowerNetworkAt(grid.P(8, 2)); ok {
+		t.Fatalf("assembler still connected after deleting its pole")
+	}
+}
+
+func TestPowerSatisfaction(t *testing.T) {
+	tests := []struct {
+		usage float64
+		ticks int
+		want  int
+	}{
+		{usage: 0, ticks: 3, want: 1},  // unpowered
+		{usage: 10, ticks: 3, want: 1}, // fully supplied
+		{usage: 20, ticks: 3, want: 0},
+		{usage: 20, ticks: 5, want: 1}, // at half speed
+	}
+	for _, test := range tests {
+		u := NewUniverse(grid.S(5, 1))
+		gen := NewGenerator("gen", 10, 20, 5)
+		gen.ConsumeFrom(Coal, grid.West)
+		ass := NewAssembler("ass", ReceiptIron(), 5, 5)
+		ass.SetPowerUsage(test.usage)
+		repeat(func() { ass.ConsumeFrom(Coal, grid.West) }, 2)
+		ass.ConsumeFrom(IronOre, grid.West)
+		u.AddObject(gen, grid.P(0, 0))
+		u.AddObject(NewPowerPole("pole", 1), grid.P(1, 0))
+		u.AddObject(ass, grid.P(2, 0))
+
+		repeat(u.Tick, test.ticks)
+		if n := ass.outStock.Amount(Iron); n != test.want {
+			t.Fatalf("usage %g: want %d, have %d", test.usage, test.want, n)
+		}
+	}
+}
+
+func TestGeneratorSupplyLimitedByEnergy(t *testing.T) {
+	g := NewGenerator("gen", 10, 1, 2)
+	g.ConsumeFrom(Iron, grid.West)
+	if n := g.fuel.Amount(Iron); n != 0 {
+		t.Fatalf("fuel %s: want %d, have %d", Iron, 0, n)
+	}
+	g.ConsumeFrom(Coal, grid.West)
+	if have := g.PowerSupply(); have != 10 {
+		t.Fatalf("supply: want %g, have %g", 10.0, have)
+	}
+	g.SetPowerLoad(0.5)
+	g.Tick()
+	// the coal is burnt and half of its energy is left
+	if have := g.PowerSupply(); have != 5 {
+		t.Fatalf("supply: want %g, have %g", 5.0, have)
+	}
+}
@@ -1,12 +1,14 @@
 package minifac
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/mazzegi/minifac/grid"
 )
 
 var _ Producer = &IncarnationProducer{}
+var _ StatusReporter = &IncarnationProducer{}
 
 func NewIncarnationProducer(name string, res Resource, rate Rate, stockCapa int) *IncarnationProducer {
 	return &IncarnationProducer{
@@ -36,6 +38,8 @@ func (p *IncarnationProducer) Tick() {
 	}
 }
 
+func (p *IncarnationProducer) isMachine() {}
+
 func (p *IncarnationProducer) Size() grid.Size {
 	return grid.S(1, 1)
 }
@@ -50,15 +54,23 @@ func (p *IncarnationProducer) Info() []string {
 		fmt.Sprintf("Resource: %s", p.resource),
 		fmt.Sprintf("Rate    : %d/%d", p.rate.count, p.rate.perTicks),
 		fmt.Sprintf("Stock   : %d", p.stock.TotalAmount()),
+		fmt.Sprintf("Status  : %s", p.Status()),
+	}
+}
+
+func (p *IncarnationProducer) Status() Status {
+	if !p.stock.CanAdd(p.resource, 1) {
+		return Status{Kind: StatusOutputBlocked}
 	}
+	return Status{Kind: StatusWorking}
 }
 
 func (p *IncarnationProducer) CanProduce() bool {
 	return p.stock.Amount(p.resource) > 0
 }
 
-func (p *IncarnationProducer) ProduceAtPositions(base grid.Position) []grid.Position {
-	return base.Neighbours()
+func (p *IncarnationProducer) ProduceAtPositions(r grid.Rectangle) []grid.Position {
+	return r.Neighbours()
 }
 
 func (p *IncarnationProducer) Produce() (Resource, bool) {
@@ -73,3 +85,47 @@ func (p *IncarnationProducer) Produce() (Resource, bool) {
 func (p *IncarnationProducer) Resource() Resource {
 	return p.resource
 }
+
+type incarnationProducerJSON struct {
+	Name         string   `json:"name"`
+	Resource     Resource `json:"resource"`
+	Rate         Rate     `json:"rate"`
+	Stock        *Stock   `json:"stock"`
+	LastProdTick int      `json:"last_prod_tick"`
+	CurrTick     int      `json:"curr_tick"`
+}
+
+func (p *IncarnationProducer) Kind() ObjectKind {
+	return KindIncarnationProducer
+}
+
+func (p *IncarnationProducer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(incarnationProducerJSON{
+		Name:         p.name,
+		Resource:     p.resource,
+		Rate:         p.rate,
+		Stock:        p.stock,
+		LastProdTick: p.lastProdTick,
+		CurrTick:     p.currTick,
+	})
+}
+
+func (p *IncarnationProducer) UnmarshalJSON(data []byte) error {
+	var pj incarnationProducerJSON
+	err := json.Unmarshal(data, &pj)
+	if err != nil {
+		return err
+	}
+	if pj.Stock == nil {
+		return fmt.Errorf("missing stock")
+	}
+	*p = IncarnationProducer{
+		name:         pj.Name,
+		resource:     pj.Resource,
+		rate:         pj.Rate,
+		stock:        pj.Stock,
+		lastProdTick: pj.LastProdTick,
+		currTick:     pj.CurrTick,
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package minifac
 
-import "golang.org/x/exp/slices"
+import (
+	"encoding/json"
+
+	"golang.org/x/exp/slices"
+)
 
 func NewQueue[T any]() *Queue[T] {
 	return &Queue[T]{
@@ -30,6 +34,11 @@ func (q *Queue[T]) Len() int {
 	return len(q.values)
 }
 
+// Values returns the queued values, front first.
+func (q *Queue[T]) Values() []T {
+	return q.values
+}
+
 func (q *Queue[T]) Peek() (T, bool) {
 	if len(q.values) == 0 {
 		var t T
@@ -37,3 +46,17 @@ func (q *Queue[T]) Peek() (T, bool) {
 	}
 	return q.values[0], true
 }
+
+func (q *Queue[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.values)
+}
+
+func (q *Queue[T]) UnmarshalJSON(data []byte) error {
+	values := []T{}
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return err
+	}
+	q.values = values
+	return nil
+}
@@ -1,17 +1,46 @@
 package minifac
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"sort"
 	"strings"
 
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
+// Amount is a number of items of one resource.
+type Amount struct {
+	Resource Resource `json:"resource"`
+	Count    int      `json:"count"`
+}
+
+// A Receipt turns its inputs into its outputs. The first output is the
+// primary one, all others are byproducts.
 type Receipt struct {
-	Input          map[Resource]int
-	Output         Resource
-	ProductionTime int
+	Input          map[Resource]int `json:"input"`
+	Outputs        []Amount         `json:"outputs"`
+	ProductionTime int              `json:"production_time"`
+}
+
+// PrimaryOutput returns the resource of the first output.
+func (r Receipt) PrimaryOutput() Resource {
+	if len(r.Outputs) == 0 {
+		return None
+	}
+	return r.Outputs[0].Resource
+}
+
+// OutputCount returns the number of items produced per cycle.
+func (r Receipt) OutputCount() int {
+	var n int
+	for _, out := range r.Outputs {
+		n += out.Count
+	}
+	return n
 }
 
 func (r Receipt) String() string {
@@ -21,19 +50,128 @@ func (r Receipt) String() string {
 	for _, res := range ress {
 		in = append(in, fmt.Sprintf("%d %s", r.Input[res], res))
 	}
-	return fmt.Sprintf("%s -> %s: %d", strings.Join(in, " + "), r.Output, r.ProductionTime)
+	var out []string
+	for _, o := range r.Outputs {
+		out = append(out, fmt.Sprintf("%d %s", o.Count, o.Resource))
+	}
+	return fmt.Sprintf("%s -> %s: %d", strings.Join(in, " + "), strings.Join(out, " + "), r.ProductionTime)
 }
 
-func AllReceipts() []Receipt {
+// receipts is the set of receipts in use. It is replaced by SetReceipts.
+var receipts = DefaultReceipts()
+
+// DefaultReceipts returns the built-in receipts.
+func DefaultReceipts() []Receipt {
 	return []Receipt{
 		ReceiptIron(),
 		ReceiptSteel(),
+		ReceiptScience(),
+	}
+}
+
+func AllReceipts() []Receipt {
+	return slices.Clone(receipts)
+}
+
+// SetReceipts replaces the set of receipts returned by AllReceipts and used
+// by ReceiptFor.
+func SetReceipts(recs []Receipt) error {
+	err := ValidateReceipts(recs)
+	if err != nil {
+		return err
+	}
+	receipts = slices.Clone(recs)
+	return nil
+}
+
+// ValidateReceipts checks that all resources are known, all amounts and
+// production times are positive and no two receipts have the same primary
+// output.
+func ValidateReceipts(recs []Receipt) error {
+	known := AllResources()
+	outputs := map[Resource]bool{}
+	for i, rec := range recs {
+		if len(rec.Input) == 0 {
+			return fmt.Errorf("receipt #%d: no input", i+1)
+		}
+		for res, cnt := range rec.Input {
+			if !slices.Contains(known, res) {
+				return fmt.Errorf("receipt #%d: unknown input resource %q", i+1, res)
+			}
+			if cnt <= 0 {
+				return fmt.Errorf("receipt #%d: non-positive amount %d of %q", i+1, cnt, res)
+			}
+		}
+		if len(rec.Outputs) == 0 {
+			return fmt.Errorf("receipt #%d: no output", i+1)
+		}
+		recOutputs := map[Resource]bool{}
+		for _, out := range rec.Outputs {
+			if !slices.Contains(known, out.Resource) {
+				return fmt.Errorf("receipt #%d: unknown output resource %q", i+1, out.Resource)
+			}
+			if out.Count <= 0 {
+				return fmt.Errorf("receipt #%d: non-positive amount %d of %q", i+1, out.Count, out.Resource)
+			}
+			if recOutputs[out.Resource] {
+				return fmt.Errorf("receipt #%d: output %q is listed twice", i+1, out.Resource)
+			}
+			recOutputs[out.Resource] = true
+		}
+		if rec.ProductionTime <= 0 {
+			return fmt.Errorf("receipt #%d: non-positive production time %d", i+1, rec.ProductionTime)
+		}
+		if outputs[rec.PrimaryOutput()] {
+			return fmt.Errorf("receipt #%d: duplicate output %q", i+1, rec.PrimaryOutput())
+		}
+		outputs[rec.PrimaryOutput()] = true
+	}
+	return nil
+}
+
+type receiptsJSON struct {
+	Receipts []Receipt `json:"receipts"`
+}
+
+// LoadReceipts reads and validates receipts in the format
+//
+//	{
+//	  "receipts": [
+//	    {
+//	      "input": {"coal": 2, "ironore": 1},
+//	      "outputs": [{"resource": "iron", "count": 1}, {"resource": "slag", "count": 1}],
+//	      "production_time": 2
+//	    }
+//	  ]
+//	}
+func LoadReceipts(r io.Reader) ([]Receipt, error) {
+	var rj receiptsJSON
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&rj)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	err = ValidateReceipts(rj.Receipts)
+	if err != nil {
+		return nil, err
 	}
+	return rj.Receipts, nil
 }
 
+func LoadReceiptsFile(path string) ([]Receipt, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", path, err)
+	}
+	defer f.Close()
+	return LoadReceipts(f)
+}
+
+// ReceiptFor returns the receipt with the primary output res.
 func ReceiptFor(res Resource) (Receipt, bool) {
-	for _, rec := range AllReceipts() {
-		if rec.Output == res {
+	for _, rec := range receipts {
+		if rec.PrimaryOutput() == res {
 			return rec, true
 		}
 	}
@@ -46,7 +184,7 @@ func ReceiptIron() Receipt {
 			Coal:    2,
 			IronOre: 1,
 		},
-		Output:         Iron,
+		Outputs:        []Amount{{Resource: Iron, Count: 1}},
 		ProductionTime: 2,
 	}
 }
@@ -57,7 +195,19 @@ func ReceiptSteel() Receipt {
 			Coal: 2,
 			Iron: 1,
 		},
-		Output:         Steel,
+		Outputs:        []Amount{{Resource: Steel, Count: 1}},
 		ProductionTime: 3,
 	}
 }
+
+// ReceiptScience produces the science packs labs use for research.
+func ReceiptScience() Receipt {
+	return Receipt{
+		Input: map[Resource]int{
+			Coal: 1,
+			Iron: 1,
+		},
+		Outputs:        []Amount{{Resource: Science, Count: 1}},
+		ProductionTime: 4,
+	}
+}
@@ -0,0 +1,205 @@
+package minifac
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+	"golang.org/x/exp/slices"
+)
+
+func TestLoadReceipts(t *testing.T) {
+	tests := []struct {
+		in    string
+		valid bool
+	}{
+		{
+			in:    `{"receipts": [{"input": {"coal": 2, "ironore": 1}, "outputs": [{"resource": "iron", "count": 1}], "production_time": 2}]}`,
+			valid: true,
+		},
+		{
+			in: `{"receipts": [
+				{"input": {"coal": 2, "ironore": 1}, "outputs": [{"resource": "iron", "count": 1}], "production_time": 2},
+				{"input": {"coal": 2, "iron": 1}, "outputs": [{"resource": "steel", "count": 1}], "production_time": 3}
+			]}`,
+			valid: true,
+		},
+		{
+			in:    `{"receipts": [{"input": {"coal": 2, "gold": 1}, "outputs": [{"resource": "iron", "count": 1}], "production_time": 2}]}`,
+			valid: false,
+		},
+		{
+			in:    `{"receipts": [{"input": {"coal": 2}, "outputs": [{"resource": "gold", "count": 1}], "production_time": 2}]}`,
+			valid: false,
+		},
+		{
+			in:    `{"receipts": [{"input": {"coal": 2}, "outputs": [{"resource": "iron", "count": 1}], "production_time": 0}]}`,
+			valid: false,
+		},
+		{
+			in:    `{"receipts": [{"input": {"coal": -1}, "outputs": [{"resource": "iron", "count": 1}], "production_time": 1}]}`,
+			valid: false,
+		},
+		{
+			in: `{"receipts": [
+				{"input": {"coal": 2, "ironore": 1}, "outputs": [{"resource": "iron", "count": 1}], "production_time": 2},
+				{"input": {"coal": 1, "ironore": 2}, "outputs": [{"resource": "iron", "count": 1}], "production_time": 3}
+			]}`,
+			valid: false,
+		},
+		{
+			in:    `{"receipts": [{"input": {"coal": 2}, "outputs": [{"resource": "iron", "count": 1}, {"resource": "stone", "count": 2}], "production_time": 1}]}`,
+			valid: true,
+		},
+		{
+			in:    `{"receipts": [{"input": {"coal": 2}, "outputs": [], "production_time": 1}]}`,
+			valid: false,
+		},
+		{
+			in:    `{"receipts": [{"input": {"coal": 2}, "outputs": [{"resource": "iron", "count": 1}, {"resource": "iron", "count": 2}], "production_time": 1}]}`,
+			valid: false,
+		},
+		{
+			in:    `{"recipes": []}`,
+			valid: false,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test_#%02d", i), func(t *testing.T) {
+			_, err := LoadReceipts(strings.NewReader(test.in))
+			if test.valid && err != nil {
+				t.Fatalf("want no error, have %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatalf("want error, have none")
+			}
+		})
+	}
+}
+
+// restoreReceipts restores the receipts in use after the test.
+func restoreReceipts(t *testing.T) {
+	saved := receipts
+	t.Cleanup(func() { receipts = saved })
+}
+
+func TestSetReceipts(t *testing.T) {
+	restoreReceipts(t)
+
+	err := SetReceipts([]Receipt{ReceiptSteel()})
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if _, ok := ReceiptFor(Iron); ok {
+		t.Fatalf("receipt for %s: want none", Iron)
+	}
+	if _, ok := ReceiptFor(Steel); !ok {
+		t.Fatalf("receipt for %s: want one, have none", Steel)
+	}
+}
+
+func TestAssemblerMultipleOutputs(t *testing.T) {
+	rec := Receipt{
+		Input:          map[Resource]int{IronOre: 1},
+		Outputs:        []Amount{{Resource: Iron, Count: 1}, {Resource: Stone, Count: 2}},
+		ProductionTime: 1,
+	}
+	a := NewAssembler("ass", rec, 5, 3)
+	a.ConsumeFrom(IronOre, grid.West)
+	a.ConsumeFrom(IronOre, grid.West)
+	repeat(a.Tick, 2)
+
+	var produced []Resource
+	for a.CanProduce() {
+		res, _ := a.Produce()
+		produced = append(produced, res)
+	}
+	want := []Resource{Iron, Stone, Stone}
+	if !slices.Equal(produced, want) {
+		t.Fatalf("produced: want %v, have %v", want, produced)
+	}
+	// the out stock has room for the next batch now
+	repeat(a.Tick, 2)
+	if a.Resource() != Iron {
+		t.Fatalf("resource: want %s, have %s", Iron, a.Resource())
+	}
+}
+
+func TestAssemblerUntakenByproduct(t *testing.T) {
+	rec := Receipt{
+		Input:          map[Resource]int{IronOre: 1},
+		Outputs:        []Amount{{Resource: Iron, Count: 1}, {Resource: Stone, Count: 1}},
+		ProductionTime: 1,
+	}
+	u := NewUniverse(grid.S(3, 1))
+	u.SetExplicitIO(false)
+	u.AddObject(NewIncarnationProducer("prod", IronOre, NewRate(1, 1), 2), grid.P(0, 0))
+	u.AddObject(NewAssembler("ass", rec, 5, 20), grid.P(1, 0))
+	u.AddObject(NewFinalizer("fin", Iron), grid.P(2, 0))
+	repeat(u.Tick, 20)
+	// nobody takes the stone, which must not hold up the iron
+	if n := u.Delivered(Iron); n < 15 {
+		t.Fatalf("delivered: want at least %d, have %d", 15, n)
+	}
+}
+
+func TestAssemblerSetReceipt(t *testing.T) {
+	a := NewAssembler("ass", ReceiptIron(), 5, 3)
+	a.ConsumeFrom(Coal, grid.West)
+	a.ConsumeFrom(Coal, grid.West)
+	a.ConsumeFrom(IronOre, grid.West)
+	a.ConsumeFrom(IronOre, grid.West)
+	a.Tick()
+
+	// the running production is cancelled and its inputs are refunded
+	a.SetReceipt(ReceiptSteel())
+	if n := a.inStocks[Coal].Amount(Coal); n != 2 {
+		t.Fatalf("coal in stock: want %d, have %d", 2, n)
+	}
+	var produced []Resource
+	for a.CanProduce() {
+		res, _ := a.Produce()
+		produced = append(produced, res)
+	}
+	want := []Resource{IronOre, IronOre}
+	if !slices.Equal(produced, want) {
+		t.Fatalf("leftovers: want %v, have %v", want, produced)
+	}
+	if !a.CanConsumeFrom(Iron, grid.West) || a.CanConsumeFrom(IronOre, grid.West) {
+		t.Fatalf("inputs not switched to %s", a.Receipt())
+	}
+}
+
+func TestAssemblerSpeed(t *testing.T) {
+	tests := []struct {
+		time  int
+		speed float64
+		ticks int
+		want  int
+	}{
+		{time: 3, speed: 1, ticks: 18, want: 12},
+		{time: 3, speed: 0.75, ticks: 20, want: 10},
+		{time: 3, speed: 1.2, ticks: 21, want: 16},
+		{time: 3, speed: 5, ticks: 9, want: 30},
+		{time: 1, speed: 1, ticks: 8, want: 16},
+		{time: 1, speed: 0.75, ticks: 8, want: 12},
+		{time: 1, speed: 1.5, ticks: 8, want: 24},
+		{time: 1, speed: 5, ticks: 2, want: 20},
+	}
+	for _, test := range tests {
+		rec := Receipt{
+			Input:          map[Resource]int{IronOre: 1},
+			Outputs:        []Amount{{Resource: Iron, Count: 2}},
+			ProductionTime: test.time,
+		}
+		a := NewAssembler("ass", rec, 20, 100)
+		a.SetSpeed(test.speed)
+		repeat(func() { a.ConsumeFrom(IronOre, grid.West) }, 20)
+		repeat(a.Tick, test.ticks)
+		if n := a.outStock.Amount(Iron); n != test.want {
+			t.Fatalf("time %d, speed %g: want %d, have %d", test.time, test.speed, test.want, n)
+		}
+	}
+}
@@ -0,0 +1,491 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mazzegi/minifac/grid"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// Technology is a node of the tech tree. It can be researched, once all
+// technologies it requires are researched, by delivering its cost to labs.
+// Researching it unlocks receipts, identified by their primary output, and
+// object kinds.
+type Technology struct {
+	ID       string           `json:"id"`
+	Name     string           `json:"name"`
+	Requires []string         `json:"requires,omitempty"`
+	Cost     map[Resource]int `json:"cost"`
+	Receipts []Resource       `json:"receipts,omitempty"`
+	Objects  []ObjectKind     `json:"objects,omitempty"`
+}
+
+// DefaultTechTree returns the built-in tech tree.
+func DefaultTechTree() []Technology {
+	return []Technology{
+		{
+			ID:      "automation",
+			Name:    "Automation",
+			Cost:    map[Resource]int{Science: 10},
+			Objects: []ObjectKind{KindInserter, KindChest},
+		},
+		{
+			ID:      "mining",
+			Name:    "Mining",
+			Cost:    map[Resource]int{Science: 10},
+			Objects: []ObjectKind{KindMiner},
+		},
+		{
+			ID:       "logistics",
+			Name:     "Logistics",
+			Requires: []string{"automation"},
+			Cost:     map[Resource]int{Science: 20},
+			Objects:  []ObjectKind{KindSplitter, KindSorter, KindTunnelEntrance, KindTunnelExit},
+		},
+		{
+			ID:       "steel",
+			Name:     "Steel Processing",
+			Requires: []string{"mining"},
+			Cost:     map[Resource]int{Science: 20, Iron: 10},
+			Receipts: []Resource{Steel},
+		},
+		{
+			ID:       "electricity",
+			Name:     "Electricity",
+			Requires: []string{"automation", "steel"},
+			Cost:     map[Resource]int{Science: 30, Steel: 5},
+			Objects:  []ObjectKind{KindGenerator, KindPowerPole},
+		},
+	}
+}
+
+// ValidateTechTree checks that ids are unique, technologies only require
+// technologies listed before them, costs are positive and all resources and
+// object kinds are known.
+func ValidateTechTree(techs []Technology) error {
+	known := AllResources()
+	ids := map[string]bool{}
+	for i, tech := range techs {
+		if tech.ID == "" {
+			return fmt.Errorf("technology #%d: empty id", i+1)
+		}
+		if ids[tech.ID] {
+			return fmt.Errorf("technology %q: duplicate id", tech.ID)
+		}
+		for _, req := range tech.Requires {
+			if !ids[req] {
+				return fmt.Errorf("technology %q: requires unknown or later technology %q", tech.ID, req)
+			}
+		}
+		ids[tech.ID] = true
+		if len(tech.Cost) == 0 {
+			return fmt.Errorf("technology %q: no cost", tech.ID)
+		}
+		for res, cnt := range tech.Cost {
+			if !slices.Contains(known, res) {
+				return fmt.Errorf("technology %q: unknown cost resource %q", tech.ID, res)
+			}
+			if cnt <= 0 {
+				return fmt.Errorf("technology %q: non-positive cost %d of %q", tech.ID, cnt, res)
+			}
+		}
+		for _, res := range tech.Receipts {
+			if !slices.Contains(known, res) {
+				return fmt.Errorf("technology %q: unknown receipt output %q", tech.ID, res)
+			}
+		}
+		for _, kind := range tech.Objects {
+			if _, ok := persistentKinds[kind]; !ok {
+				return fmt.Errorf("technology %q: unknown object kind %q", tech.ID, kind)
+			}
+		}
+	}
+	return nil
+}
+
+type techTreeJSON struct {
+	Technologies []Technology `json:"technologies"`
+}
+
+// LoadTechTree reads and validates a tech tree in the format
+//
+//	{
+//	  "technologies": [
+//	    {"id": "automation", "name": "Automation", "cost": {"science": 10}, "objects": ["inserter"]},
+//	    {"id": "steel", "name": "Steel", "requires": ["automation"], "cost": {"science": 20}, "receipts": ["steel"]}
+//	  ]
+//	}
+func LoadTechTree(r io.Reader) ([]Technology, error) {
+	var tj techTreeJSON
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&tj)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	err = ValidateTechTree(tj.Technologies)
+	if err != nil {
+		return nil, err
+	}
+	return tj.Technologies, nil
+}
+
+func LoadTechTreeFile(path string) ([]Technology, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", path, err)
+	}
+	defer f.Close()
+	return LoadTechTree(f)
+}
+
+// NewResearch creates the research state of a tech tree, with nothing
+// researched yet.
+func NewResearch(techs []Technology) (*Research, error) {
+	err := ValidateTechTree(techs)
+	if err != nil {
+		return nil, err
+	}
+	r := &Research{
+		techs:      techs,
+		researched: map[string]bool{},
+		progress:   map[string]map[Resource]int{},
+	}
+	r.selectCurrent()
+	return r, nil
+}
+
+// Research tracks which technologies of a tech tree are researched, and the
+// progress of the current one.
+type Research struct {
+	techs      []Technology
+	researched map[string]bool
+	completed  []string // researched technologies in order of completion
+	progress   map[string]map[Resource]int
+	current    string
+}
+
+func (r *Research) Technologies() []Technology {
+	return slices.Clone(r.techs)
+}
+
+func (r *Research) lookup(id string) (Technology, bool) {
+	for _, tech := range r.techs {
+		if tech.ID == id {
+			return tech, true
+		}
+	}
+	return Technology{}, false
+}
+
+func (r *Research) Researched(id string) bool {
+	return r.researched[id]
+}
+
+// Completed returns the ids of the researched technologies in the order they
+// have been completed.
+func (r *Research) Completed() []string {
+	return slices.Clone(r.completed)
+}
+
+// Available reports whether the technology is not researched yet, but all
+// technologies it requires are.
+func (r *Research) Available(id string) bool {
+	tech, ok := r.lookup(id)
+	if !ok || r.researched[id] {
+		return false
+	}
+	for _, req := range tech.Requires {
+		if !r.researched[req] {
+			return false
+		}
+	}
+	return true
+}
+
+// Current returns the technology, which is researched at the moment.
+func (r *Research) Current() (Technology, bool) {
+	if r.current == "" {
+		return Technology{}, false
+	}
+	return r.lookup(r.current)
+}
+
+// SetCurrent switches the research to an available technology. The progress
+// of the previous one is kept, as are the items in the labs.
+func (r *Research) SetCurrent(id string) error {
+	if !r.Available(id) {
+		return fmt.Errorf("technology %q is not available", id)
+	}
+	r.current = id
+	return nil
+}
+
+// NextAvailable returns the available technology following the current one
+// in the tree.
+func (r *Research) NextAvailable() (Technology, bool) {
+	start := 0
+	for i, tech := range r.techs {
+		if tech.ID == r.current {
+			start = i + 1
+		}
+	}
+	for i := range r.techs {
+		tech := r.techs[(start+i)%len(r.techs)]
+		if r.Available(tech.ID) {
+			return tech, true
+		}
+	}
+	return Technology{}, false
+}
+
+// selectCurrent selects the first available technology, if there is no
+// current one.
+func (r *Research) selectCurrent() {
+	if r.current != "" && r.Available(r.current) {
+		return
+	}
+	r.current = ""
+	for _, tech := range r.techs {
+		if r.Available(tech.ID) {
+			r.current = tech.ID
+			return
+		}
+	}
+}
+
+// Progress returns the number of items of each resource delivered for the
+// technology.
+func (r *Research) Progress(id string) map[Resource]int {
+	return maps.Clone(r.progress[id])
+}
+
+// Needs reports whether the current technology needs more of res.
+func (r *Research) Needs(res Resource) bool {
+	tech, ok := r.Current()
+	if !ok {
+		return false
+	}
+	return r.progress[tech.ID][res] < tech.Cost[res]
+}
+
+// Needed returns the resources the current technology needs more of, in
+// sorted order.
+func (r *Research) Needed() []Resource {
+	tech, ok := r.Current()
+	if !ok {
+		return nil
+	}
+	var ress []Resource
+	for res := range tech.Cost {
+		if r.Needs(res) {
+			ress = append(ress, res)
+		}
+	}
+	sort.Slice(ress, func(i, j int) bool { return ress[i] < ress[j] })
+	return ress
+}
+
+// deliver adds one item of res to the current technology and completes it,
+// when its whole cost is delivered.
+func (r *Research) deliver(res Resource) {
+	if !r.Needs(res) {
+		return
+	}
+	tech, _ := r.Current()
+	if r.progress[tech.ID] == nil {
+		r.progress[tech.ID] = map[Resource]int{}
+	}
+	r.progress[tech.ID][res]++
+	for cres, cnt := range tech.Cost {
+		if r.progress[tech.ID][cres] < cnt {
+			return
+		}
+	}
+	r.researched[tech.ID] = true
+	r.completed = append(r.completed, tech.ID)
+	r.selectCurrent()
+}
+
+// ReceiptUnlocked reports whether no technology, which is not researched
+// yet, unlocks the receipt with the primary output res.
+func (r *Research) ReceiptUnlocked(res Resource) bool {
+	for _, tech := range r.techs {
+		if !r.researched[tech.ID] && slices.Contains(tech.Receipts, res) {
+			return false
+		}
+	}
+	return true
+}
+
+// ObjectUnlocked reports whether no technology, which is not researched
+// yet, unlocks the object kind.
+func (r *Research) ObjectUnlocked(kind ObjectKind) bool {
+	for _, tech := range r.techs {
+		if !r.researched[tech.ID] && slices.Contains(tech.Objects, kind) {
+			return false
+		}
+	}
+	return true
+}
+
+// Info lists all technologies in tree order with their state: [x] for
+// researched, [>] for the current one, [ ] for available and [-] for locked
+// ones.
+func (r *Research) Info() []string {
+	info := []string{"Research:"}
+	for _, tech := range r.techs {
+		var line string
+		switch {
+		case r.researched[tech.ID]:
+			line = fmt.Sprintf("[x] %s", tech.Name)
+		case tech.ID == r.current:
+			line = fmt.Sprintf("[>] %s: %s", tech.Name, r.costInfo(tech))
+		case r.Available(tech.ID):
+			line = fmt.Sprintf("[ ] %s: %s", tech.Name, r.costInfo(tech))
+		default:
+			line = fmt.Sprintf("[-] %s (requires %s)", tech.Name, strings.Join(tech.Requires, ", "))
+		}
+		info = append(info, line)
+	}
+	return info
+}
+
+func (r *Research) costInfo(tech Technology) string {
+	ress := maps.Keys(tech.Cost)
+	sort.Slice(ress, func(i, j int) bool { return ress[i] < ress[j] })
+	var cs []string
+	for _, res := range ress {
+		cs = append(cs, fmt.Sprintf("%d/%d %s", r.progress[tech.ID][res], tech.Cost[res], res.DisplayName()))
+	}
+	return strings.Join(cs, ", ")
+}
+
+// SetResearch enables research with the given state. With nil, research is
+// disabled and everything is unlocked. Objects already placed are kept, even
+// if they are not researched yet.
+func (u *Universe) SetResearch(r *Research) {
+	u.research = r
+	if r == nil {
+		return
+	}
+	for _, obj := range u.grid.Objects() {
+		u.preset[obj.Value] = true
+	}
+}
+
+func (u *Universe) Research() (*Research, bool) {
+	return u.research, u.research != nil
+}
+
+// ReceiptUnlocked reports whether the receipt with the primary output res
+// may be used.
+func (u *Universe) ReceiptUnlocked(res Resource) bool {
+	return u.research == nil || u.research.ReceiptUnlocked(res)
+}
+
+// ObjectUnlocked reports whether objects of the kind may be placed.
+func (u *Universe) ObjectUnlocked(kind ObjectKind) bool {
+	return u.research == nil || u.research.ObjectUnlocked(kind)
+}
+
+// updateResearch hands one item of each lab over to the current technology.
+// Then it lets the labs take only what is still outstanding, counting the
+// items already in the labs.
+func (u *Universe) updateResearch(objs []*grid.Object[Object]) {
+	var labs []*Lab
+	for _, obj := range objs {
+		if lab, ok := obj.Value.(*Lab); ok {
+			labs = append(labs, lab)
+		}
+	}
+	if u.research == nil {
+		for _, lab := range labs {
+			lab.researching = false
+			lab.demand = nil
+		}
+		return
+	}
+	r := u.research
+	for _, lab := range labs {
+		lab.researching = false
+		for _, res := range lab.stock.Resources() {
+			if r.Needs(res) {
+				lab.stock.Take(res, 1)
+				lab.researching = true
+				r.deliver(res)
+				break
+			}
+		}
+	}
+
+	demand := map[Resource]int{}
+	if tech, ok := r.Current(); ok {
+		for res, cnt := range tech.Cost {
+			demand[res] = cnt - r.progress[tech.ID][res]
+		}
+	}
+	// items in the labs count against the demand, even those kept from a
+	// technology switched away from
+	for _, lab := range labs {
+		for _, res := range lab.stock.Resources() {
+			demand[res] -= lab.stock.Amount(res)
+		}
+	}
+	for res, n := range demand {
+		if n <= 0 {
+			delete(demand, res)
+		}
+	}
+	for _, lab := range labs {
+		lab.demand = demand
+	}
+}
+
+type researchJSON struct {
+	Technologies []Technology                `json:"technologies"`
+	Researched   []string                    `json:"researched"` // in order of completion
+	Progress     map[string]map[Resource]int `json:"progress"`
+	Current      string                      `json:"current"`
+}
+
+func (r *Research) MarshalJSON() ([]byte, error) {
+	return json.Marshal(researchJSON{
+		Technologies: r.techs,
+		Researched:   r.completed,
+		Progress:     r.progress,
+		Current:      r.current,
+	})
+}
+
+func (r *Research) UnmarshalJSON(data []byte) error {
+	var rj researchJSON
+	err := json.Unmarshal(data, &rj)
+	if err != nil {
+		return err
+	}
+	nr, err := NewResearch(rj.Technologies)
+	if err != nil {
+		return err
+	}
+	for _, id := range rj.Researched {
+		if _, ok := nr.lookup(id); !ok {
+			return fmt.Errorf("unknown researched technology %q", id)
+		}
+		nr.researched[id] = true
+		nr.completed = append(nr.completed, id)
+	}
+	for id, p := range rj.Progress {
+		nr.progress[id] = p
+	}
+	nr.current = rj.Current
+	nr.selectCurrent()
+	*r = *nr
+	return nil
+}
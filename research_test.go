@@ -0,0 +1,134 @@
+package minifac
+
+import (
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func TestResearchUnlocks(t *testing.T) {
+	r, err := NewResearch([]Technology{
+		{ID: "mining", Name: "Mining", Cost: map[Resource]int{Science: 4}, Objects: []ObjectKind{KindMiner}},
+		{ID: "steel", Name: "Steel", Requires: []string{"mining"}, Cost: map[Resource]int{Science: 2}, Receipts: []Resource{Steel}},
+	})
+	if err != nil {
+		t.Fatalf("new research: %v", err)
+	}
+	u := NewUniverse(grid.S(4, 2))
+	u.SetExplicitIO(false)
+	u.SetResearch(r)
+	u.SetDeposit(grid.P(0, 1), Deposit{Resource: Coal, Amount: 10})
+	u.AddObject(NewIncarnationProducer("prod", Science, NewRate(1, 1), 2), grid.P(0, 0))
+	u.AddObject(NewLab("lab", 2), grid.P(1, 0))
+
+	if err := u.AddObject(NewMiner("miner", grid.S(1, 1), NewRate(1, 1), 2), grid.P(0, 1)); err == nil {
+		t.Fatalf("add locked miner: want error, have none")
+	}
+	if err := u.AddObject(NewAssembler("ass", ReceiptSteel(), 5, 5), grid.P(3, 0)); err == nil {
+		t.Fatalf("add assembler with locked receipt: want error, have none")
+	}
+
+	tests := []struct {
+		ticks   int
+		mining  bool
+		steel   bool
+		current string
+	}{
+		{ticks: 2, mining: false, steel: false, current: "mining"},
+		{ticks: 4, mining: true, steel: false, current: "steel"},
+		{ticks: 4, mining: true, steel: true, current: ""},
+	}
+	for i, test := range tests {
+		repeat(u.Tick, test.ticks)
+		if have := r.Researched("mining"); have != test.mining {
+			t.Fatalf("#%d: mining researched: want %t, have %t", i, test.mining, have)
+		}
+		if have := r.Researched("steel"); have != test.steel {
+			t.Fatalf("#%d: steel researched: want %t, have %t", i, test.steel, have)
+		}
+		var current string
+		if tech, ok := r.Current(); ok {
+			current = tech.ID
+		}
+		if current != test.current {
+			t.Fatalf("#%d: current: want %q, have %q", i, test.current, current)
+		}
+	}
+
+	if err := u.AddObject(NewMiner("miner", grid.S(1, 1), NewRate(1, 1), 2), grid.P(0, 1)); err != nil {
+		t.Fatalf("add researched miner: %v", err)
+	}
+	if err := u.AddObject(NewAssembler("ass", ReceiptSteel(), 5, 5), grid.P(3, 0)); err != nil {
+		t.Fatalf("add assembler with researched receipt: %v", err)
+	}
+}
+
+func TestValidateTechTree(t *testing.T) {
+	if err := ValidateTechTree(DefaultTechTree()); err != nil {
+		t.Fatalf("default tech tree: %v", err)
+	}
+	tests := []struct {
+		name  string
+		techs []Technology
+	}{
+		{name: "duplicate", techs: []Technology{
+			{ID: "a", Cost: map[Resource]int{Science: 1}},
+			{ID: "a", Cost: map[Resource]int{Science: 1}},
+		}},
+		{name: "later requirement", techs: []Technology{
+			{ID: "a", Requires: []string{"b"}, Cost: map[Resource]int{Science: 1}},
+			{ID: "b", Cost: map[Resource]int{Science: 1}},
+		}},
+		{name: "no cost", techs: []Technology{{ID: "a"}}},
+		{name: "unknown object", techs: []Technology{
+			{ID: "a", Cost: map[Resource]int{Science: 1}, Objects: []ObjectKind{"rocket"}},
+		}},
+	}
+	for _, test := range tests {
+		if err := ValidateTechTree(test.techs); err == nil {
+			t.Fatalf("%s: want error, have none", test.name)
+		}
+	}
+}
+
+func TestLabsTakeOutstandingCost(t *testing.T) {
+	r, err := NewResearch([]Technology{
+		{ID: "steel", Name: "Steel", Cost: map[Resource]int{Iron: 6, Science: 1}},
+		{ID: "mining", Name: "Mining", Cost: map[Resource]int{Science: 2}},
+	})
+	if err != nil {
+		t.Fatalf("new research: %v", err)
+	}
+	u := NewUniverse(grid.S(2, 2))
+	u.SetExplicitIO(false)
+	u.SetResearch(r)
+	lab1, lab2 := NewLab("lab_1", 10), NewLab("lab_2", 10)
+	u.AddObject(NewIncarnationProducer("prod_1", Iron, NewRate(1, 1), 2), grid.P(0, 0))
+	u.AddObject(lab1, grid.P(1, 0))
+	u.AddObject(NewIncarnationProducer("prod_2", Iron, NewRate(1, 1), 2), grid.P(0, 1))
+	u.AddObject(lab2, grid.P(1, 1))
+
+	labIron := func() int { return lab1.Amount(Iron) + lab2.Amount(Iron) }
+	for i := 0; i < 20; i++ {
+		u.Tick()
+		if have := r.Progress("steel")[Iron] + labIron(); have > 6 {
+			t.Fatalf("tick %d: iron delivered and in labs: want at most %d, have %d", i+1, 6, have)
+		}
+	}
+	if have := r.Progress("steel")[Iron]; have != 6 {
+		t.Fatalf("iron delivered: want %d, have %d", 6, have)
+	}
+
+	// items of a technology switched away from are kept
+	lab1.stock.Add(Iron, 3)
+	if err := r.SetCurrent("mining"); err != nil {
+		t.Fatalf("set current: %v", err)
+	}
+	u.Tick()
+	if have := labIron(); have != 3 {
+		t.Fatalf("iron in labs after switch: want %d, have %d", 3, have)
+	}
+	if lab1.CanConsumeFrom(Iron, grid.West) {
+		t.Fatalf("lab after switch: want no intake of iron")
+	}
+}
@@ -1,5 +1,14 @@
 package minifac
 
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/exp/slices"
+)
+
 type Resource string
 
 const (
@@ -10,13 +19,165 @@ const (
 	IronOre Resource = "ironore"
 	Iron    Resource = "iron"
 	Steel   Resource = "steel"
+	Science Resource = "science"
+)
+
+type ResourceCategory string
+
+const (
+	CategoryBase         ResourceCategory = "base"
+	CategoryIntermediate ResourceCategory = "intermediate"
+	CategoryFinal        ResourceCategory = "final"
 )
 
+// ResourceInfo describes a resource in the registry.
+type ResourceInfo struct {
+	ID          Resource         `json:"id"`
+	DisplayName string           `json:"display_name"`
+	Category    ResourceCategory `json:"category"`
+	// Icon is the path of the icon image, relative to the UI assets or the
+	// working directory
+	Icon string `json:"icon"`
+	// StackSize is the number of items, which fit into one slot of a chest
+	StackSize int `json:"stack_size"`
+}
+
+func (info ResourceInfo) validate() error {
+	if info.ID == "" || info.ID == None {
+		return fmt.Errorf("invalid resource id %q", info.ID)
+	}
+	switch info.Category {
+	case CategoryBase, CategoryIntermediate, CategoryFinal:
+	default:
+		return fmt.Errorf("resource %q: invalid category %q", info.ID, info.Category)
+	}
+	if info.StackSize <= 0 {
+		return fmt.Errorf("resource %q: non-positive stack size %d", info.ID, info.StackSize)
+	}
+	return nil
+}
+
+// resourceRegistry holds the known resources in order of registration.
+type resourceRegistry struct {
+	ids   []Resource
+	infos map[Resource]ResourceInfo
+}
+
+var resources = &resourceRegistry{
+	infos: map[Resource]ResourceInfo{},
+}
+
+func init() {
+	err := RegisterResources([]ResourceInfo{
+		{ID: Wood, DisplayName: "Wood", Category: CategoryBase, Icon: "wood.png", StackSize: 100},
+		{ID: Stone, DisplayName: "Stone", Category: CategoryBase, Icon: "stone.png", StackSize: 50},
+		{ID: Coal, DisplayName: "Coal", Category: CategoryBase, Icon: "coal.png", StackSize: 50},
+		{ID: IronOre, DisplayName: "Iron Ore", Category: CategoryBase, Icon: "ironore.png", StackSize: 50},
+		{ID: Iron, DisplayName: "Iron", Category: CategoryIntermediate, Icon: "iron.png", StackSize: 100},
+		{ID: Steel, DisplayName: "Steel", Category: CategoryFinal, Icon: "steel.png", StackSize: 100},
+		{ID: Science, DisplayName: "Science", Category: CategoryIntermediate, Icon: "science.png", StackSize: 200},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RegisterResources adds resources to the registry. Either all or none of
+// them are added.
+func RegisterResources(infos []ResourceInfo) error {
+	ids := map[Resource]bool{}
+	for _, info := range infos {
+		err := info.validate()
+		if err != nil {
+			return err
+		}
+		if _, ok := resources.infos[info.ID]; ok || ids[info.ID] {
+			return fmt.Errorf("resource %q is already registered", info.ID)
+		}
+		ids[info.ID] = true
+	}
+	for _, info := range infos {
+		resources.ids = append(resources.ids, info.ID)
+		resources.infos[info.ID] = info
+	}
+	return nil
+}
+
+func LookupResource(res Resource) (ResourceInfo, bool) {
+	info, ok := resources.infos[res]
+	return info, ok
+}
+
+// DisplayName returns the display name of a registered resource and the id
+// otherwise.
+func (r Resource) DisplayName() string {
+	if info, ok := LookupResource(r); ok && info.DisplayName != "" {
+		return info.DisplayName
+	}
+	return string(r)
+}
+
+// StackSize returns the stack size of a registered resource and 1 otherwise.
+func (r Resource) StackSize() int {
+	if info, ok := LookupResource(r); ok && info.StackSize > 0 {
+		return info.StackSize
+	}
+	return 1
+}
+
+func AllResources() []Resource {
+	return slices.Clone(resources.ids)
+}
+
+func ResourcesOfCategory(cat ResourceCategory) []Resource {
+	var ress []Resource
+	for _, id := range resources.ids {
+		if resources.infos[id].Category == cat {
+			ress = append(ress, id)
+		}
+	}
+	return ress
+}
+
 func BaseResources() []Resource {
-	return []Resource{
-		Wood,
-		Stone,
-		Coal,
-		IronOre,
+	return ResourcesOfCategory(CategoryBase)
+}
+
+type resourcesJSON struct {
+	Resources []ResourceInfo `json:"resources"`
+}
+
+// LoadResources reads resource definitions in the format
+//
+//	{
+//	  "resources": [
+//	    {"id": "copper", "display_name": "Copper", "category": "intermediate", "icon": "icons/copper.png", "stack_size": 100}
+//	  ]
+//	}
+//
+// The resources still need to be registered with RegisterResources.
+func LoadResources(r io.Reader) ([]ResourceInfo, error) {
+	var rj resourcesJSON
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&rj)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	for _, info := range rj.Resources {
+		err := info.validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rj.Resources, nil
+}
+
+func LoadResourcesFile(path string) ([]ResourceInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", path, err)
 	}
+	defer f.Close()
+	return LoadResources(f)
 }
@@ -0,0 +1,57 @@
+package minifac
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// restoreResources restores the registry after the test.
+func restoreResources(t *testing.T) {
+	saved := resources
+	resources = &resourceRegistry{
+		ids:   slices.Clone(saved.ids),
+		infos: maps.Clone(saved.infos),
+	}
+	t.Cleanup(func() { resources = saved })
+}
+
+func TestRegisterResources(t *testing.T) {
+	restoreResources(t)
+	infos, err := LoadResources(strings.NewReader(`{"resources": [
+		{"id": "copperore", "display_name": "Copper Ore", "category": "base", "icon": "copperore.png", "stack_size": 50},
+		{"id": "copper", "display_name": "Copper", "category": "intermediate", "icon": "copper.png", "stack_size": 100}
+	]}`))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	err = RegisterResources(infos)
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if !slices.Contains(BaseResources(), Resource("copperore")) {
+		t.Fatalf("base resources: want %q, have %v", "copperore", BaseResources())
+	}
+	if have := Resource("copper").DisplayName(); have != "Copper" {
+		t.Fatalf("display name: want %q, have %q", "Copper", have)
+	}
+	// registering again must fail
+	if err := RegisterResources(infos); err == nil {
+		t.Fatalf("register again: want error, have none")
+	}
+
+	_, err = LoadResources(strings.NewReader(`{"resources": [
+		{"id": "gold", "display_name": "Gold", "category": "shiny", "icon": "gold.png", "stack_size": 10}
+	]}`))
+	if err == nil {
+		t.Fatalf("load invalid category: want error, have none")
+	}
+	_, err = LoadResources(strings.NewReader(`{"resources": [
+		{"id": "gold", "display_name": "Gold", "category": "base", "icon": "gold.png"}
+	]}`))
+	if err == nil {
+		t.Fatalf("load without stack size: want error, have none")
+	}
+}
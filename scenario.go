@@ -0,0 +1,286 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type GoalKind string
+
+const (
+	// GoalDeliver is reached when Amount items of Resource have been
+	// delivered to finalizers.
+	GoalDeliver GoalKind = "deliver"
+	// GoalSustain is reached when Rate items of Resource per tick have been
+	// delivered to finalizers on average over the last Ticks ticks.
+	GoalSustain GoalKind = "sustain"
+)
+
+// Goal is an objective of a scenario. Goals with Within > 0 fail, if they are
+// not reached within that many ticks.
+type Goal struct {
+	Kind     GoalKind `json:"kind"`
+	Resource Resource `json:"resource"`
+	Amount   int      `json:"amount,omitempty"`
+	Rate     float64  `json:"rate,omitempty"`
+	Ticks    int      `json:"ticks,omitempty"`
+	Within   int      `json:"within,omitempty"`
+}
+
+func (g Goal) String() string {
+	var s string
+	switch g.Kind {
+	case GoalDeliver:
+		s = fmt.Sprintf("deliver %d %s", g.Amount, g.Resource.DisplayName())
+	case GoalSustain:
+		s = fmt.Sprintf("sustain %g %s/tick for %d ticks", g.Rate, g.Resource.DisplayName(), g.Ticks)
+	default:
+		s = string(g.Kind)
+	}
+	if g.Within > 0 {
+		s += fmt.Sprintf(" within %d ticks", g.Within)
+	}
+	return s
+}
+
+func (g Goal) validate() error {
+	if _, ok := LookupResource(g.Resource); !ok {
+		return fmt.Errorf("goal %q: unknown resource %q", g.Kind, g.Resource)
+	}
+	if g.Within < 0 {
+		return fmt.Errorf("goal %q: negative within %d", g.Kind, g.Within)
+	}
+	switch g.Kind {
+	case GoalDeliver:
+		if g.Amount <= 0 {
+			return fmt.Errorf("goal %q: non-positive amount %d", g.Kind, g.Amount)
+		}
+	case GoalSustain:
+		if g.Rate <= 0 || g.Ticks <= 0 {
+			return fmt.Errorf("goal %q: invalid rate %g for %d ticks", g.Kind, g.Rate, g.Ticks)
+		}
+	default:
+		return fmt.Errorf("unknown goal kind %q", g.Kind)
+	}
+	return nil
+}
+
+// Scenario is a map together with goals, which have to be reached.
+type Scenario struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Map is the file the universe is loaded from, relative to the scenario
+	// file.
+	Map   string `json:"map"`
+	Goals []Goal `json:"goals"`
+}
+
+func (s *Scenario) Validate() error {
+	if len(s.Goals) == 0 {
+		return fmt.Errorf("scenario %q has no goals", s.Name)
+	}
+	for _, g := range s.Goals {
+		err := g.validate()
+		if err != nil {
+			return fmt.Errorf("scenario %q: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+func LoadScenario(r io.Reader) (*Scenario, error) {
+	var s Scenario
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&s)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	err = s.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// LoadScenarioFile loads a scenario and resolves its map relative to the
+// scenario file.
+func LoadScenarioFile(path string) (*Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", path, err)
+	}
+	defer f.Close()
+	s, err := LoadScenario(f)
+	if err != nil {
+		return nil, err
+	}
+	if s.Map != "" && !filepath.IsAbs(s.Map) {
+		s.Map = filepath.Join(filepath.Dir(path), s.Map)
+	}
+	return s, nil
+}
+
+// Start loads the map of the scenario and starts a run on it.
+func (s *Scenario) Start() (*ScenarioRun, error) {
+	if s.Map == "" {
+		return nil, fmt.Errorf("scenario %q has no map", s.Name)
+	}
+	u, err := LoadFile(s.Map)
+	if err != nil {
+		return nil, err
+	}
+	return NewScenarioRun(s, u), nil
+}
+
+type ScenarioStatus string
+
+const (
+	ScenarioRunning ScenarioStatus = "running"
+	ScenarioWon     ScenarioStatus = "won"
+	ScenarioLost    ScenarioStatus = "lost"
+)
+
+// GoalProgress is the state of a goal in a run.
+type GoalProgress struct {
+	Goal      Goal           `json:"goal"`
+	Status    ScenarioStatus `json:"status"`
+	Delivered int            `json:"delivered"`
+	// Rate is the average rate over the last Goal.Ticks ticks of sustain
+	// goals.
+	Rate float64 `json:"rate,omitempty"`
+	// DoneAt is the tick of the run the goal has been won or lost at.
+	DoneAt int `json:"done_at,omitempty"`
+
+	lastDelivered int
+	window        []int // delivered items per tick of sustain goals
+	next          int
+	filled        int
+}
+
+// NewScenarioRun starts a run of the scenario on u. Items delivered before
+// the run started do not count.
+func NewScenarioRun(s *Scenario, u *Universe) *ScenarioRun {
+	r := &ScenarioRun{
+		scenario: s,
+		universe: u,
+	}
+	for _, g := range s.Goals {
+		gp := &GoalProgress{
+			Goal:          g,
+			Status:        ScenarioRunning,
+			lastDelivered: u.Delivered(g.Resource),
+		}
+		if g.Kind == GoalSustain {
+			gp.window = make([]int, g.Ticks)
+		}
+		r.goals = append(r.goals, gp)
+	}
+	return r
+}
+
+// ScenarioRun ticks a universe and evaluates the goals of a scenario after
+// each tick. The scenario is won, when all goals are reached, and lost as
+// soon as one goal fails. The universe keeps running after that.
+type ScenarioRun struct {
+	scenario *Scenario
+	universe *Universe
+	tick     int
+	goals    []*GoalProgress
+}
+
+func (r *ScenarioRun) Scenario() *Scenario {
+	return r.scenario
+}
+
+func (r *ScenarioRun) Universe() *Universe {
+	return r.universe
+}
+
+// Ticks returns the number of ticks since the run started.
+func (r *ScenarioRun) Ticks() int {
+	return r.tick
+}
+
+func (r *ScenarioRun) Tick() {
+	r.universe.Tick()
+	r.tick++
+	for _, gp := range r.goals {
+		r.evaluate(gp)
+	}
+}
+
+func (r *ScenarioRun) evaluate(gp *GoalProgress) {
+	delivered := r.universe.Delivered(gp.Goal.Resource)
+	delta := delivered - gp.lastDelivered
+	gp.lastDelivered = delivered
+	gp.Delivered += delta
+	if gp.Status != ScenarioRunning {
+		return
+	}
+
+	var reached bool
+	switch gp.Goal.Kind {
+	case GoalDeliver:
+		reached = gp.Delivered >= gp.Goal.Amount
+	case GoalSustain:
+		gp.window[gp.next] = delta
+		gp.next = (gp.next + 1) % len(gp.window)
+		gp.filled = Min(gp.filled+1, len(gp.window))
+		var sum int
+		for _, n := range gp.window {
+			sum += n
+		}
+		gp.Rate = float64(sum) / float64(len(gp.window))
+		reached = gp.filled == len(gp.window) && gp.Rate+1e-9 >= gp.Goal.Rate
+	}
+	switch {
+	case reached:
+		gp.Status, gp.DoneAt = ScenarioWon, r.tick
+	case gp.Goal.Within > 0 && r.tick >= gp.Goal.Within:
+		gp.Status, gp.DoneAt = ScenarioLost, r.tick
+	}
+}
+
+// Goals returns the progress of all goals.
+func (r *ScenarioRun) Goals() []GoalProgress {
+	gps := make([]GoalProgress, 0, len(r.goals))
+	for _, gp := range r.goals {
+		gps = append(gps, *gp)
+	}
+	return gps
+}
+
+func (r *ScenarioRun) Status() ScenarioStatus {
+	status := ScenarioWon
+	for _, gp := range r.goals {
+		switch gp.Status {
+		case ScenarioLost:
+			return ScenarioLost
+		case ScenarioRunning:
+			status = ScenarioRunning
+		}
+	}
+	return status
+}
+
+func (r *ScenarioRun) Info() []string {
+	info := []string{
+		fmt.Sprintf("Scenario: %s", r.scenario.Name),
+		fmt.Sprintf("Status  : %s (tick %d)", r.Status(), r.tick),
+	}
+	for _, gp := range r.goals {
+		var progress string
+		switch gp.Goal.Kind {
+		case GoalDeliver:
+			progress = fmt.Sprintf("%d/%d", Min(gp.Delivered, gp.Goal.Amount), gp.Goal.Amount)
+		case GoalSustain:
+			progress = fmt.Sprintf("%.2f/%g per tick", gp.Rate, gp.Goal.Rate)
+		}
+		info = append(info, fmt.Sprintf("Goal: %s: %s: %s", gp.Goal, progress, gp.Status))
+	}
+	return info
+}
@@ -0,0 +1,48 @@
+package minifac
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScenarioRun(t *testing.T) {
+	tests := []struct {
+		goal Goal
+		want ScenarioStatus
+	}{
+		{Goal{Kind: GoalDeliver, Resource: Iron, Amount: 10, Within: 100}, ScenarioWon},
+		{Goal{Kind: GoalDeliver, Resource: Iron, Amount: 100, Within: 50}, ScenarioLost},
+		{Goal{Kind: GoalDeliver, Resource: Steel, Amount: 1}, ScenarioRunning},
+		{Goal{Kind: GoalSustain, Resource: Iron, Rate: 0.5, Ticks: 20, Within: 100}, ScenarioWon},
+		{Goal{Kind: GoalSustain, Resource: Iron, Rate: 1, Ticks: 20, Within: 100}, ScenarioLost},
+	}
+	for _, test := range tests {
+		u, err := ParseTextMap(strings.NewReader("P>F\n\nio: direct\nP: producer iron rate=1/2\nF: finalizer iron\n"))
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		s := &Scenario{Name: "test", Goals: []Goal{test.goal}}
+		if err := s.Validate(); err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+		r := NewScenarioRun(s, u)
+		repeat(r.Tick, 200)
+		if have := r.Status(); have != test.want {
+			t.Fatalf("%s: want %s, have %s", test.goal, test.want, have)
+		}
+	}
+}
+
+func TestLoadScenarioInvalid(t *testing.T) {
+	tests := []string{
+		`{"name": "none", "goals": []}`,
+		`{"name": "kind", "goals": [{"kind": "hoard", "resource": "iron"}]}`,
+		`{"name": "amount", "goals": [{"kind": "deliver", "resource": "iron"}]}`,
+		`{"name": "res", "goals": [{"kind": "deliver", "resource": "gold", "amount": 1}]}`,
+	}
+	for _, test := range tests {
+		if _, err := LoadScenario(strings.NewReader(test)); err == nil {
+			t.Fatalf("load %s: want error, have none", test)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mazzegi/minifac/grid"
+	"golang.org/x/exp/slices"
+)
+
+var _ ProducerConsumer = &Sorter{}
+var _ StatusReporter = &Sorter{}
+
+// NewSorter creates a sorter facing dir. It accepts items from its back and
+// sends the resources in filter to side and all others straight ahead.
+func NewSorter(name string, dir grid.Direction, side grid.Direction, filter []Resource, capa int) (*Sorter, error) {
+	if side != dir.Left() && side != dir.Right() {
+		return nil, fmt.Errorf("side %s is not next to direction %s", side, dir)
+	}
+	s := &Sorter{
+		name:     name,
+		dir:      dir,
+		side:     side,
+		capacity: capa,
+		buffer:   NewQueue[Resource](),
+	}
+	s.SetFilter(filter)
+	return s, nil
+}
+
+type Sorter struct {
+	name     string
+	dir      grid.Direction
+	side     grid.Direction
+	filter   []Resource
+	capacity int
+	buffer   *Queue[Resource]
+	waiting  int
+}
+
+func (s *Sorter) Size() grid.Size {
+	return grid.S(1, 1)
+}
+
+func (s *Sorter) Dir() grid.Direction {
+	return s.dir
+}
+
+func (s *Sorter) Side() grid.Direction {
+	return s.side
+}
+
+func (s *Sorter) Filter() []Resource {
+	return slices.Clone(s.filter)
+}
+
+// SetFilter sets the resources, which are sent to the side.
+func (s *Sorter) SetFilter(filter []Resource) {
+	s.filter = slices.Clone(filter)
+	sort.Slice(s.filter, func(i, j int) bool { return s.filter[i] < s.filter[j] })
+	s.filter = slices.Compact(s.filter)
+}
+
+func (s *Sorter) Name() string {
+	return s.name
+}
+
+func (s *Sorter) Info() []string {
+	return []string{
+		fmt.Sprintf("Sorter: %s", s.name),
+		fmt.Sprintf("Status: %s", s.Status()),
+		fmt.Sprintf("Filter: %v -> %s", s.filter, s.side),
+		fmt.Sprintf("Items : %d/%d", s.buffer.Len(), s.capacity),
+	}
+}
+
+func (s *Sorter) Tick() {
+	if s.buffer.Len() > 0 {
+		s.waiting++
+	}
+}
+
+func (s *Sorter) Status() Status {
+	switch {
+	case s.buffer.Len() == 0:
+		return Status{Kind: StatusIdle}
+	case s.waiting > 0:
+		return Status{Kind: StatusOutputBlocked}
+	default:
+		return Status{Kind: StatusWorking}
+	}
+}
+
+// ProduceAtPositions returns the side for filtered resources and the front
+// for all others.
+func (s *Sorter) ProduceAtPositions(r grid.Rectangle) []grid.Position {
+	if slices.Contains(s.filter, s.Resource()) {
+		return []grid.Position{r.Position.Neighbour(s.side)}
+	}
+	return []grid.Position{r.Position.Neighbour(s.dir)}
+}
+
+func (s *Sorter) ConsumeAtPositions(r grid.Rectangle) []grid.Position {
+	return []grid.Position{r.Position}
+}
+
+func (s *Sorter) ConsumeFrom(res Resource, dir grid.Direction) {
+	if !s.CanConsumeFrom(res, dir) {
+		return
+	}
+	s.buffer.Enqueue(res)
+}
+
+func (s *Sorter) CanConsumeFrom(res Resource, dir grid.Direction) bool {
+	return dir == s.dir.Opposite() && s.CanConsumeAny()
+}
+
+func (s *Sorter) CanConsumeAny() bool {
+	return s.buffer.Len() < s.capacity
+}
+
+func (s *Sorter) Produce() (Resource, bool) {
+	res, ok := s.buffer.Dequeue()
+	if ok {
+		s.waiting = 0
+	}
+	return res, ok
+}
+
+func (s *Sorter) CanProduce() bool {
+	return s.buffer.Len() > 0
+}
+
+func (s *Sorter) Resource() Resource {
+	res, ok := s.buffer.Peek()
+	if !ok {
+		return None
+	}
+	return res
+}
+
+type sorterJSON struct {
+	Name     string           `json:"name"`
+	Dir      grid.Direction   `json:"dir"`
+	Side     grid.Direction   `json:"side"`
+	Filter   []Resource       `json:"filter"`
+	Capacity int              `json:"capacity"`
+	Buffer   *Queue[Resource] `json:"buffer"`
+	Waiting  int              `json:"waiting"`
+}
+
+func (s *Sorter) Kind() ObjectKind {
+	return KindSorter
+}
+
+func (s *Sorter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sorterJSON{
+		Name:     s.name,
+		Dir:      s.dir,
+		Side:     s.side,
+		Filter:   s.filter,
+		Capacity: s.capacity,
+		Buffer:   s.buffer,
+		Waiting:  s.waiting,
+	})
+}
+
+func (s *Sorter) UnmarshalJSON(data []byte) error {
+	var sj sorterJSON
+	err := json.Unmarshal(data, &sj)
+	if err != nil {
+		return err
+	}
+	ns, err := NewSorter(sj.Name, sj.Dir, sj.Side, sj.Filter, sj.Capacity)
+	if err != nil {
+		return err
+	}
+	*s = *ns
+	if sj.Buffer != nil {
+		s.buffer = sj.Buffer
+	}
+	s.waiting = sj.Waiting
+	return nil
+}
@@ -0,0 +1,36 @@
+package minifac
+
+import (
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func TestSorter(t *testing.T) {
+	u := NewUniverse(grid.S(4, 3))
+	u.SetExplicitIO(false)
+	so, err := NewSorter("sort", grid.East, grid.North, []Resource{IronOre}, 1)
+	if err != nil {
+		t.Fatalf("new sorter: %v", err)
+	}
+	u.AddObject(NewIncarnationProducer("prod_coal", Coal, NewRate(1, 4), 1), grid.P(0, 1))
+	u.AddObject(NewIncarnationProducer("prod_ore", IronOre, NewRate(1, 4), 1), grid.P(1, 2))
+	u.AddObject(NewConveyor("conv", grid.East, 1), grid.P(1, 1))
+	u.AddObject(so, grid.P(2, 1))
+	u.AddObject(NewFinalizer("fin_ore", IronOre), grid.P(2, 0))
+	u.AddObject(NewFinalizer("fin_coal", Coal), grid.P(3, 1))
+	repeat(u.Tick, 100)
+
+	if consumed(u, "fin_ore") == 0 || consumed(u, "fin_coal") == 0 {
+		t.Fatalf("consumed: want both sorted, have ore %d, coal %d", consumed(u, "fin_ore"), consumed(u, "fin_coal"))
+	}
+	if so.Status().Kind == StatusOutputBlocked {
+		t.Fatalf("sorter is blocked")
+	}
+}
+
+func TestSorterSide(t *testing.T) {
+	if _, err := NewSorter("sort", grid.East, grid.West, nil, 1); err == nil {
+		t.Fatalf("side behind the sorter: want error, have none")
+	}
+}
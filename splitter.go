@@ -0,0 +1,216 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mazzegi/minifac/grid"
+	"golang.org/x/exp/slices"
+)
+
+var _ ProducerConsumer = &Splitter{}
+var _ Distributor = &Splitter{}
+var _ StatusReporter = &Splitter{}
+
+// NewSplitter creates a splitter facing dir. It accepts items from its back
+// and hands them out alternately to its left and right side and, with three
+// outputs, also to its front.
+func NewSplitter(name string, dir grid.Direction, outputs int, capa int) *Splitter {
+	s := &Splitter{
+		name:     name,
+		dir:      dir,
+		capacity: capa,
+		buffer:   NewQueue[Resource](),
+	}
+	s.outputs = []grid.Direction{dir.Left(), dir.Right()}
+	if outputs >= 3 {
+		s.outputs = []grid.Direction{dir.Left(), dir, dir.Right()}
+	}
+	return s
+}
+
+type Splitter struct {
+	name     string
+	dir      grid.Direction
+	outputs  []grid.Direction
+	capacity int
+	buffer   *Queue[Resource]
+	next     int            // index of the output to be served next
+	priority grid.Direction // output served first, if it is not blocked
+	waiting  int
+}
+
+func (s *Splitter) Size() grid.Size {
+	return grid.S(1, 1)
+}
+
+func (s *Splitter) Dir() grid.Direction {
+	return s.dir
+}
+
+func (s *Splitter) Outputs() []grid.Direction {
+	return slices.Clone(s.outputs)
+}
+
+func (s *Splitter) Priority() grid.Direction {
+	return s.priority
+}
+
+// SetPriority sets the output, which is served first. With grid.None the
+// outputs are served in turn.
+func (s *Splitter) SetPriority(d grid.Direction) error {
+	if d != grid.None && !slices.Contains(s.outputs, d) {
+		return fmt.Errorf("%s is not an output of the splitter", d)
+	}
+	s.priority = d
+	return nil
+}
+
+func (s *Splitter) Name() string {
+	return s.name
+}
+
+func (s *Splitter) Info() []string {
+	return []string{
+		fmt.Sprintf("Splitter: %s", s.name),
+		fmt.Sprintf("Status  : %s", s.Status()),
+		fmt.Sprintf("Outputs : %v", s.outputs),
+		fmt.Sprintf("Priority: %s", s.priority),
+		fmt.Sprintf("Items   : %d/%d", s.buffer.Len(), s.capacity),
+	}
+}
+
+func (s *Splitter) Tick() {
+	if s.buffer.Len() > 0 {
+		s.waiting++
+	}
+}
+
+func (s *Splitter) Status() Status {
+	switch {
+	case s.buffer.Len() == 0:
+		return Status{Kind: StatusIdle}
+	case s.waiting > 0:
+		return Status{Kind: StatusOutputBlocked}
+	default:
+		return Status{Kind: StatusWorking}
+	}
+}
+
+// ProduceAtPositions returns the outputs in the order they are served.
+func (s *Splitter) ProduceAtPositions(r grid.Rectangle) []grid.Position {
+	var poss []grid.Position
+	if s.priority != grid.None {
+		poss = append(poss, r.Position.Neighbour(s.priority))
+	}
+	for i := range s.outputs {
+		d := s.outputs[(s.next+i)%len(s.outputs)]
+		if d == s.priority {
+			continue
+		}
+		poss = append(poss, r.Position.Neighbour(d))
+	}
+	return poss
+}
+
+// ProducedTo advances the turn to the output after dir.
+func (s *Splitter) ProducedTo(dir grid.Direction) {
+	if dir == s.priority {
+		return
+	}
+	if idx := slices.Index(s.outputs, dir); idx >= 0 {
+		s.next = (idx + 1) % len(s.outputs)
+	}
+}
+
+func (s *Splitter) ConsumeAtPositions(r grid.Rectangle) []grid.Position {
+	return []grid.Position{r.Position}
+}
+
+func (s *Splitter) ConsumeFrom(res Resource, dir grid.Direction) {
+	if !s.CanConsumeFrom(res, dir) {
+		return
+	}
+	s.buffer.Enqueue(res)
+}
+
+func (s *Splitter) CanConsumeFrom(res Resource, dir grid.Direction) bool {
+	return dir == s.dir.Opposite() && s.CanConsumeAny()
+}
+
+func (s *Splitter) CanConsumeAny() bool {
+	return s.buffer.Len() < s.capacity
+}
+
+func (s *Splitter) Produce() (Resource, bool) {
+	res, ok := s.buffer.Dequeue()
+	if ok {
+		s.waiting = 0
+	}
+	return res, ok
+}
+
+func (s *Splitter) CanProduce() bool {
+	return s.buffer.Len() > 0
+}
+
+func (s *Splitter) Resource() Resource {
+	res, ok := s.buffer.Peek()
+	if !ok {
+		return None
+	}
+	return res
+}
+
+type splitterJSON struct {
+	Name     string           `json:"name"`
+	Dir      grid.Direction   `json:"dir"`
+	Outputs  []grid.Direction `json:"outputs"`
+	Capacity int              `json:"capacity"`
+	Buffer   *Queue[Resource] `json:"buffer"`
+	Next     int              `json:"next"`
+	Priority grid.Direction   `json:"priority"`
+	Waiting  int              `json:"waiting"`
+}
+
+func (s *Splitter) Kind() ObjectKind {
+	return KindSplitter
+}
+
+func (s *Splitter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(splitterJSON{
+		Name:     s.name,
+		Dir:      s.dir,
+		Outputs:  s.outputs,
+		Capacity: s.capacity,
+		Buffer:   s.buffer,
+		Next:     s.next,
+		Priority: s.priority,
+		Waiting:  s.waiting,
+	})
+}
+
+func (s *Splitter) UnmarshalJSON(data []byte) error {
+	var sj splitterJSON
+	err := json.Unmarshal(data, &sj)
+	if err != nil {
+		return err
+	}
+	if len(sj.Outputs) == 0 {
+		return fmt.Errorf("missing outputs")
+	}
+	*s = *NewSplitter(sj.Name, sj.Dir, len(sj.Outputs), sj.Capacity)
+	if !slices.Equal(s.outputs, sj.Outputs) {
+		return fmt.Errorf("invalid outputs %v for direction %s", sj.Outputs, sj.Dir)
+	}
+	err = s.SetPriority(sj.Priority)
+	if err != nil {
+		return err
+	}
+	if sj.Buffer != nil {
+		s.buffer = sj.Buffer
+	}
+	s.next = sj.Next % len(s.outputs)
+	s.waiting = sj.Waiting
+	return nil
+}
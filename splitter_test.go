@@ -0,0 +1,54 @@
+package minifac
+
+import (
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func TestSplitter(t *testing.T) {
+	tests := []struct {
+		name      string
+		southKind Resource // resource accepted in the south
+		priority  grid.Direction
+		north     int
+		south     int
+	}{
+		{name: "alternate", southKind: Coal, priority: grid.None, north: 20, south: 20},
+		{name: "skip-blocked", southKind: Iron, priority: grid.None, north: 40, south: 0},
+		{name: "priority", southKind: Coal, priority: grid.South, north: 0, south: 40},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u := NewUniverse(grid.S(3, 3))
+			u.SetExplicitIO(false)
+			sp := NewSplitter("split", grid.East, 2, 1)
+			if err := sp.SetPriority(test.priority); err != nil {
+				t.Fatalf("set priority: %v", err)
+			}
+			u.AddObject(NewIncarnationProducer("prod", Coal, NewRate(1, 1), 1), grid.P(0, 1))
+			u.AddObject(sp, grid.P(1, 1))
+			u.AddObject(NewFinalizer("north", Coal), grid.P(1, 0))
+			u.AddObject(NewFinalizer("south", test.southKind), grid.P(1, 2))
+			repeat(u.Tick, 41)
+			if n := consumed(u, "north"); n != test.north {
+				t.Fatalf("north: want %d, have %d", test.north, n)
+			}
+			if n := consumed(u, "south"); n != test.south {
+				t.Fatalf("south: want %d, have %d", test.south, n)
+			}
+		})
+	}
+}
+
+func TestSplitterAcceptsFromBack(t *testing.T) {
+	sp := NewSplitter("split", grid.North, 3, 1)
+	for _, d := range []grid.Direction{grid.North, grid.East, grid.West} {
+		if sp.CanConsumeFrom(Coal, d) {
+			t.Fatalf("consume from %s: want false, have true", d)
+		}
+	}
+	if !sp.CanConsumeFrom(Coal, grid.South) {
+		t.Fatalf("consume from %s: want true, have false", grid.South)
+	}
+}
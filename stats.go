@@ -0,0 +1,127 @@
+package minifac
+
+import "github.com/mazzegi/minifac/grid"
+
+const (
+	// StatsWindow is the number of ticks accumulated into one sample
+	StatsWindow = 10
+	// StatsSamples is the number of samples kept per object
+	StatsSamples = 60
+)
+
+// Counters are accumulated per object over a number of ticks.
+type Counters struct {
+	Produced       int `json:"produced"`
+	Consumed       int `json:"consumed"`
+	ProducingTicks int `json:"producing_ticks"`
+	BlockedTicks   int `json:"blocked_ticks"`
+}
+
+func (c *Counters) add(o Counters) {
+	c.Produced += o.Produced
+	c.Consumed += o.Consumed
+	c.ProducingTicks += o.ProducingTicks
+	c.BlockedTicks += o.BlockedTicks
+}
+
+// ObjectStats holds the counters of an object over its whole lifetime and
+// for the last StatsSamples windows of StatsWindow ticks each.
+type ObjectStats struct {
+	Total   Counters
+	Ticks   int
+	current Counters
+	samples [StatsSamples]Counters
+	next    int // index in samples of the next window to be finished
+	filled  int // number of finished windows in samples
+}
+
+func (s *ObjectStats) record(c Counters) {
+	s.current.add(c)
+	s.Total.add(c)
+}
+
+func (s *ObjectStats) finishWindow() {
+	s.samples[s.next] = s.current
+	s.current = Counters{}
+	s.next = (s.next + 1) % StatsSamples
+	s.filled = Min(s.filled+1, StatsSamples)
+}
+
+// Utilization returns the share of ticks the object was working.
+func (s *ObjectStats) Utilization() float64 {
+	return s.share(s.Total.ProducingTicks)
+}
+
+// Blocked returns the share of ticks the output of the object was blocked.
+func (s *ObjectStats) Blocked() float64 {
+	return s.share(s.Total.BlockedTicks)
+}
+
+func (s *ObjectStats) share(n int) float64 {
+	if s.Ticks == 0 {
+		return 0
+	}
+	return float64(n) / float64(s.Ticks)
+}
+
+// objectStatsJSON holds the lifetime counters of an object. The samples are
+// not saved.
+type objectStatsJSON struct {
+	Total Counters `json:"total"`
+	Ticks int      `json:"ticks"`
+}
+
+// Samples returns the counters of the finished windows, oldest first.
+func (s *ObjectStats) Samples() []Counters {
+	cs := make([]Counters, 0, s.filled)
+	start := (s.next - s.filled + StatsSamples) % StatsSamples
+	for i := 0; i < s.filled; i++ {
+		cs = append(cs, s.samples[(start+i)%StatsSamples])
+	}
+	return cs
+}
+
+// Stats returns the statistics recorded for the object o. They are removed,
+// when the object is deleted.
+func (u *Universe) Stats(o Object) (*ObjectStats, bool) {
+	s, ok := u.stats[o]
+	return s, ok
+}
+
+// Delivered returns the number of items of res consumed by finalizers.
+func (u *Universe) Delivered(res Resource) int {
+	return u.delivered[res]
+}
+
+func (u *Universe) record(o Object, c Counters) {
+	s, ok := u.stats[o]
+	if !ok {
+		s = &ObjectStats{}
+		u.stats[o] = s
+	}
+	s.record(c)
+}
+
+// recordTick records the status of all objects at the end of a tick.
+func (u *Universe) recordTick(objs []*grid.Object[Object]) {
+	for _, obj := range objs {
+		var c Counters
+		if sr, ok := obj.Value.(StatusReporter); ok {
+			switch sr.Status().Kind {
+			case StatusWorking:
+				c.ProducingTicks = 1
+			case StatusOutputBlocked:
+				c.BlockedTicks = 1
+			}
+		}
+		u.record(obj.Value, c)
+	}
+	for _, s := range u.stats {
+		s.Ticks++
+	}
+	if u.tick%StatsWindow == 0 {
+		for _, s := range u.stats {
+			s.finishWindow()
+		}
+	}
+}
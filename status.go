@@ -0,0 +1,38 @@
+package minifac
+
+import (
+	"fmt"
+	"strings"
+)
+
+type StatusKind string
+
+const (
+	StatusIdle          StatusKind = "idle"
+	StatusWorking       StatusKind = "working"
+	StatusInputStarved  StatusKind = "input-starved"
+	StatusOutputBlocked StatusKind = "output-blocked"
+)
+
+// Status describes what an object is doing at the end of a tick.
+type Status struct {
+	Kind StatusKind
+	// Missing holds the resources which are lacking, if Kind is StatusInputStarved
+	Missing []Resource
+}
+
+func (s Status) String() string {
+	if s.Kind != StatusInputStarved || len(s.Missing) == 0 {
+		return string(s.Kind)
+	}
+	var ms []string
+	for _, res := range s.Missing {
+		ms = append(ms, string(res))
+	}
+	return fmt.Sprintf("%s (%s)", s.Kind, strings.Join(ms, ", "))
+}
+
+// StatusReporter is implemented by objects, which report their status.
+type StatusReporter interface {
+	Status() Status
+}
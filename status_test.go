@@ -0,0 +1,33 @@
+package minifac
+
+import (
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func TestAssemblerStatus(t *testing.T) {
+	a := NewAssembler("ass", ReceiptSteel(), 5, 1)
+	if st := a.Status(); st.Kind != StatusIdle {
+		t.Fatalf("empty: want %s, have %s", StatusIdle, st)
+	}
+
+	a.ConsumeFrom(Coal, grid.West)
+	st := a.Status()
+	if st.Kind != StatusInputStarved || len(st.Missing) != 2 {
+		t.Fatalf("one coal: want %s with 2 missing, have %s", StatusInputStarved, st)
+	}
+
+	a.ConsumeFrom(Coal, grid.West)
+	a.ConsumeFrom(Iron, grid.West)
+	a.Tick()
+	if st := a.Status(); st.Kind != StatusWorking {
+		t.Fatalf("producing: want %s, have %s", StatusWorking, st)
+	}
+
+	// the single output slot gets filled and nobody takes it
+	repeat(a.Tick, ReceiptSteel().ProductionTime)
+	if st := a.Status(); st.Kind != StatusOutputBlocked {
+		t.Fatalf("full: want %s, have %s", StatusOutputBlocked, st)
+	}
+}
@@ -1,5 +1,10 @@
 package minifac
 
+import (
+	"encoding/json"
+	"sort"
+)
+
 func NewStock(capa int) *Stock {
 	return &Stock{
 		total:     0,
@@ -8,21 +13,71 @@ func NewStock(capa int) *Stock {
 	}
 }
 
+// NewSlotStock creates a stock of the given number of slots. Each slot holds
+// one stack of a single resource.
+func NewSlotStock(slots int) *Stock {
+	s := NewStock(slots)
+	s.slots = true
+	return s
+}
+
 type Stock struct {
 	resources map[Resource]int
 	total     int
-	capacity  int
+	// number of items, or of slots in a slot stock
+	capacity int
+	slots    bool
+}
+
+// room returns the number of items of res, which can still be added.
+func (s *Stock) room(res Resource) int {
+	if !s.slots {
+		return s.capacity - s.total
+	}
+	size := res.StackSize()
+	var partial int
+	if n := s.resources[res] % size; n > 0 {
+		partial = size - n
+	}
+	return partial + Max(0, s.capacity-s.UsedSlots())*size
+}
+
+// UsedSlots returns the number of slots taken by the items in stock. For a
+// stock without slots it is the number of items.
+func (s *Stock) UsedSlots() int {
+	if !s.slots {
+		return s.total
+	}
+	var used int
+	for res, n := range s.resources {
+		size := res.StackSize()
+		used += (n + size - 1) / size
+	}
+	return used
+}
+
+// Full reports whether no item of any resource can be added.
+func (s *Stock) Full() bool {
+	if s.UsedSlots() < s.capacity {
+		return false
+	}
+	for res := range s.resources {
+		if s.room(res) > 0 {
+			return false
+		}
+	}
+	return true
 }
 
 func (s *Stock) Add(res Resource, n int) (added int) {
-	add := Min(s.capacity-s.total, n)
+	add := Max(0, Min(s.room(res), n))
 	s.total += add
 	s.resources[res] += add
 	return add
 }
 
 func (s *Stock) CanAdd(res Resource, n int) bool {
-	return s.total+n <= s.capacity
+	return n <= s.room(res)
 }
 
 func (s *Stock) Take(res Resource, n int) (taken int) {
@@ -39,3 +94,44 @@ func (s *Stock) Amount(res Resource) int {
 func (s *Stock) TotalAmount() int {
 	return s.total
 }
+
+// Resources returns the resources in stock in sorted order.
+func (s *Stock) Resources() []Resource {
+	var ress []Resource
+	for res, n := range s.resources {
+		if n > 0 {
+			ress = append(ress, res)
+		}
+	}
+	sort.Slice(ress, func(i, j int) bool { return ress[i] < ress[j] })
+	return ress
+}
+
+type stockJSON struct {
+	Capacity  int              `json:"capacity"`
+	Resources map[Resource]int `json:"resources"`
+	Slots     bool             `json:"slots,omitempty"`
+}
+
+func (s *Stock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stockJSON{
+		Capacity:  s.capacity,
+		Resources: s.resources,
+		Slots:     s.slots,
+	})
+}
+
+func (s *Stock) UnmarshalJSON(data []byte) error {
+	var sj stockJSON
+	err := json.Unmarshal(data, &sj)
+	if err != nil {
+		return err
+	}
+	*s = *NewStock(sj.Capacity)
+	s.slots = sj.Slots
+	for res, n := range sj.Resources {
+		s.resources[res] = n
+		s.total += n
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package minifac
+
+import (
+	"fmt"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+// Terrain is the ground objects are placed on.
+type Terrain byte
+
+const (
+	TerrainGround Terrain = iota
+	TerrainWater
+	TerrainOre
+	TerrainRock
+)
+
+var terrainNames = map[Terrain]string{
+	TerrainGround: "ground",
+	TerrainWater:  "water",
+	TerrainOre:    "ore",
+	TerrainRock:   "rock",
+}
+
+func (t Terrain) String() string {
+	if s, ok := terrainNames[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("terrain(%d)", t)
+}
+
+func ParseTerrain(s string) (Terrain, error) {
+	for t, ts := range terrainNames {
+		if ts == s {
+			return t, nil
+		}
+	}
+	return TerrainGround, fmt.Errorf("invalid terrain %q", s)
+}
+
+func (t Terrain) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *Terrain) UnmarshalText(text []byte) error {
+	pt, err := ParseTerrain(string(text))
+	if err != nil {
+		return err
+	}
+	*t = pt
+	return nil
+}
+
+// SetTerrain sets the terrain at p. Objects already placed at p are kept.
+func (u *Universe) SetTerrain(p grid.Position, t Terrain) error {
+	if !u.ContainsPosition(p) {
+		return fmt.Errorf("position %s is out of bounds", p)
+	}
+	if _, ok := terrainNames[t]; !ok {
+		return fmt.Errorf("invalid terrain %s", t)
+	}
+	u.terrain.Set(p, t)
+	return nil
+}
+
+// TerrainAt returns the terrain at p. Positions out of bounds are ground.
+func (u *Universe) TerrainAt(p grid.Position) Terrain {
+	return u.terrain.At(p)
+}
+
+// PositionedTerrain is a terrain together with its position.
+type PositionedTerrain struct {
+	Position grid.Position `json:"position"`
+	Terrain  Terrain       `json:"terrain"`
+}
+
+// AllTerrain returns the terrain of all tiles, which are not ground, row by
+// row.
+func (u *Universe) AllTerrain() []PositionedTerrain {
+	var pts []PositionedTerrain
+	size := u.Size()
+	for y := 0; y < size.DY; y++ {
+		for x := 0; x < size.DX; x++ {
+			if t := u.TerrainAt(grid.P(x, y)); t != TerrainGround {
+				pts = append(pts, PositionedTerrain{Position: grid.P(x, y), Terrain: t})
+			}
+		}
+	}
+	return pts
+}
+
+// terrainAllows reports whether o may be placed on a tile of terrain t.
+// Obstacles may be placed anywhere, nothing else on water or rock. Miners
+// need ore.
+func terrainAllows(o Object, t Terrain) bool {
+	switch o.(type) {
+	case *Obstacle:
+		return true
+	case *Miner:
+		return t == TerrainOre
+	}
+	return t == TerrainGround || t == TerrainOre
+}
+
+// checkPlacement reports whether o is researched and may be placed on r.
+// Objects placed before research was enabled need not be researched. When
+// restoring a saved universe, miners may be left on depleted deposits.
+func (u *Universe) checkPlacement(o Object, r grid.Rectangle, restoring bool) error {
+	if p, ok := o.(persistent); ok && !u.preset[o] && !u.ObjectUnlocked(p.Kind()) {
+		return fmt.Errorf("%s %q is not researched yet", p.Kind(), o.Name())
+	}
+	if a, ok := o.(*Assembler); ok && !u.preset[o] && !u.ReceiptUnlocked(a.Receipt().PrimaryOutput()) {
+		return fmt.Errorf("receipt for %s of %q is not researched yet", a.Receipt().PrimaryOutput(), o.Name())
+	}
+	for _, p := range r.Positions() {
+		if t := u.TerrainAt(p); !terrainAllows(o, t) {
+			return fmt.Errorf("%q cannot be placed on %s at %s", o.Name(), t, p)
+		}
+	}
+	if _, ok := o.(*Miner); ok && !restoring {
+		for _, p := range r.Positions() {
+			if _, ok := u.DepositAt(p); ok {
+				return nil
+			}
+		}
+		return fmt.Errorf("miner %q must be placed on a deposit", o.Name())
+	}
+	return nil
+}
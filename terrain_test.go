@@ -0,0 +1,32 @@
+package minifac
+
+import (
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func TestTerrainPlacement(t *testing.T) {
+	tests := []struct {
+		terrain Terrain
+		obj     Object
+		ok      bool
+	}{
+		{TerrainGround, NewConveyor("conv", grid.East, 1), true},
+		{TerrainWater, NewConveyor("conv", grid.East, 1), false},
+		{TerrainRock, NewTrashbin("trash"), false},
+		{TerrainWater, NewObstacle("wall", ObstacleWall), true},
+		{TerrainOre, NewConveyor("conv", grid.East, 1), true},
+		{TerrainGround, NewMiner("miner", grid.S(1, 1), NewRate(1, 1), 2), false},
+		{TerrainOre, NewMiner("miner", grid.S(1, 1), NewRate(1, 1), 2), true},
+	}
+	for _, test := range tests {
+		u := NewUniverse(grid.S(1, 1))
+		u.SetDeposit(grid.P(0, 0), Deposit{Resource: Coal, Amount: 10})
+		u.SetTerrain(grid.P(0, 0), test.terrain)
+		err := u.AddObject(test.obj, grid.P(0, 0))
+		if ok := err == nil; ok != test.ok {
+			t.Fatalf("place %T on %s: want %t, have %t (%v)", test.obj, test.terrain, test.ok, ok, err)
+		}
+	}
+}
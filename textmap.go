@@ -0,0 +1,749 @@
+package minifac
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mazzegi/minifac/grid"
+	"golang.org/x/exp/slices"
+)
+
+// A text map describes a layout with one character per tile, followed by an
+// empty line and a legend. E.g.:
+//
+//	#########
+//	#P>>>>A>T
+//	#C>>>>^.#
+//	#########
+//
+//	io: direct
+//	P: producer ironore rate=1/2 stock=2
+//	C: producer coal
+//	A: assembler iron in=5 out=5
+//	T: trashbin
+//
+// The characters '.' and ' ' are empty tiles, '~' is water, '%' is rock,
+// '#' are walls and '>', 'v', '<', '^' are conveyors with capacity 1. All
+// other characters must be defined in the legend. An object larger than one
+// tile (size=WxH) is drawn by repeating its character over all of its tiles.
+//
+// Legend entries:
+//
+//	producer <resource> [rate=<count>/<ticks>] [stock=<n>]
+//	assembler <output> [in=<n>] [out=<n>] [size=<w>x<h>] [speed=<f>] [power=<f>]
+//	finalizer <resource>
+//	trashbin
+//	conveyor <direction> [capa=<n>] [speed=<ticks per tile>] [tier=<name>]
+//	obstacle [type]
+//	generator [power=<f>] [burn=<n>] [fuel=<n>]
+//	pole [radius=<n>]
+//	splitter <direction> [outputs=2|3] [capa=<n>] [priority=<direction>]
+//	sorter <direction> [<resource>,...] [side=<direction>] [capa=<n>]
+//	tunnel <direction> [length=<n>] [capa=<n>]
+//	tunnelexit <direction>
+//	inserter <direction> [items=<n>] [swing=<n>]
+//	chest [resource] [capa=<slots>]
+//	deposit <resource> [amount=<n>]
+//	miner <resource> [amount=<n>] [rate=<count>/<ticks>] [stock=<n>] [size=<w>x<h>]
+//	lab [capa=<n>]
+//
+// A deposit is a tile without an object. A miner is placed on a deposit of
+// the given amount on each of its tiles.
+//
+// Machines exchange items only with inserters, unless the legend contains
+// the line "io: direct" as above.
+
+const (
+	textMapEmpty = '.'
+	textMapWall  = '#'
+
+	textMapIOKey      = "io"
+	textMapIODirect   = "direct"
+	textMapIOExplicit = "explicit"
+)
+
+var textMapTerrain = map[rune]Terrain{
+	'~': TerrainWater,
+	'%': TerrainRock,
+}
+
+var textMapConveyors = map[rune]grid.Direction{
+	'>': grid.East,
+	'v': grid.South,
+	'<': grid.West,
+	'^': grid.North,
+}
+
+// textMapLetters are used by WriteTextMap for legend entries.
+const textMapLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuwxyz0123456789"
+
+type textMapSpec struct {
+	kind string
+	args []string
+	opts map[string]string
+}
+
+func parseTextMapSpec(s string) (textMapSpec, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return textMapSpec{}, fmt.Errorf("empty legend entry")
+	}
+	spec := textMapSpec{
+		kind: fields[0],
+		opts: map[string]string{},
+	}
+	for _, f := range fields[1:] {
+		if k, v, ok := strings.Cut(f, "="); ok {
+			spec.opts[k] = v
+			continue
+		}
+		spec.args = append(spec.args, f)
+	}
+	return spec, nil
+}
+
+func (s textMapSpec) arg(i int) (string, error) {
+	if i >= len(s.args) {
+		return "", fmt.Errorf("%s: missing argument #%d", s.kind, i+1)
+	}
+	return s.args[i], nil
+}
+
+func (s textMapSpec) resourceArg(i int) (Resource, error) {
+	a, err := s.arg(i)
+	if err != nil {
+		return None, err
+	}
+	res := Resource(a)
+	if !slices.Contains(AllResources(), res) {
+		return None, fmt.Errorf("%s: unknown resource %q", s.kind, a)
+	}
+	return res, nil
+}
+
+func (s textMapSpec) directionArg(i int) (grid.Direction, error) {
+	a, err := s.arg(i)
+	if err != nil {
+		return grid.None, err
+	}
+	dir, err := grid.ParseDirection(a)
+	if err != nil || dir == grid.None {
+		return grid.None, fmt.Errorf("%s: invalid direction %q", s.kind, a)
+	}
+	return dir, nil
+}
+
+func (s textMapSpec) intOpt(key string, def int) (int, error) {
+	v, ok := s.opts[key]
+	if !ok {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s: invalid %s %q", s.kind, key, v)
+	}
+	return n, nil
+}
+
+func (s textMapSpec) floatOpt(key string, def float64) (float64, error) {
+	v, ok := s.opts[key]
+	if !ok {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		return 0, fmt.Errorf("%s: invalid %s %q", s.kind, key, v)
+	}
+	return f, nil
+}
+
+func (s textMapSpec) rateOpt(key string, def Rate) (Rate, error) {
+	v, ok := s.opts[key]
+	if !ok {
+		return def, nil
+	}
+	cs, ps, ok := strings.Cut(v, "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("%s: invalid %s %q", s.kind, key, v)
+	}
+	c, cerr := strconv.Atoi(cs)
+	p, perr := strconv.Atoi(ps)
+	if cerr != nil || perr != nil || c <= 0 || p <= 0 {
+		return Rate{}, fmt.Errorf("%s: invalid %s %q", s.kind, key, v)
+	}
+	return NewRate(c, p), nil
+}
+
+func (s textMapSpec) sizeOpt(key string, def grid.Size) (grid.Size, error) {
+	v, ok := s.opts[key]
+	if !ok {
+		return def, nil
+	}
+	ws, hs, ok := strings.Cut(v, "x")
+	if !ok {
+		return grid.Size{}, fmt.Errorf("%s: invalid %s %q", s.kind, key, v)
+	}
+	w, werr := strconv.Atoi(ws)
+	h, herr := strconv.Atoi(hs)
+	if werr != nil || herr != nil || w <= 0 || h <= 0 {
+		return grid.Size{}, fmt.Errorf("%s: invalid %s %q", s.kind, key, v)
+	}
+	return grid.S(w, h), nil
+}
+
+// newObject creates the object described by the spec. The position is only
+// used to name it.
+func (s textMapSpec) newObject(p grid.Position) (Object, error) {
+	name := func(prefix string) string {
+		return fmt.Sprintf("%s_%d_%d", prefix, p.X, p.Y)
+	}
+	switch s.kind {
+	case "producer":
+		res, err := s.resourceArg(0)
+		if err != nil {
+			return nil, err
+		}
+		rate, err := s.rateOpt("rate", NewRate(1, 2))
+		if err != nil {
+			return nil, err
+		}
+		stock, err := s.intOpt("stock", 2)
+		if err != nil {
+			return nil, err
+		}
+		return NewIncarnationProducer(name("prod_"+string(res)), res, rate, stock), nil
+	case "assembler":
+		res, err := s.resourceArg(0)
+		if err != nil {
+			return nil, err
+		}
+		rec, ok := ReceiptFor(res)
+		if !ok {
+			return nil, fmt.Errorf("assembler: no receipt for %q", res)
+		}
+		inCapa, err := s.intOpt("in", 5)
+		if err != nil {
+			return nil, err
+		}
+		outCapa, err := s.intOpt("out", 5)
+		if err != nil {
+			return nil, err
+		}
+		size, err := s.sizeOpt("size", grid.S(1, 1))
+		if err != nil {
+			return nil, err
+		}
+		speed, err := s.floatOpt("speed", 1)
+		if err != nil {
+			return nil, err
+		}
+		power, err := s.floatOpt("power", 0)
+		if err != nil {
+			return nil, err
+		}
+		a := NewSizedAssembler(name("ass_"+string(res)), size, rec, inCapa, outCapa)
+		a.SetSpeed(speed)
+		a.SetPowerUsage(power)
+		return a, nil
+	case "finalizer":
+		res, err := s.resourceArg(0)
+		if err != nil {
+			return nil, err
+		}
+		return NewFinalizer(name("fin_"+string(res)), res), nil
+	case "trashbin":
+		return NewTrashbin(name("trash")), nil
+	case "conveyor":
+		dir, err := s.directionArg(0)
+		if err != nil {
+			return nil, err
+		}
+		if tn, ok := s.opts["tier"]; ok {
+			tier, ok := LookupConveyorTier(tn)
+			if !ok {
+				return nil, fmt.Errorf("conveyor: unknown tier %q", tn)
+			}
+			return NewConveyorOfTier(name("conv"), dir, tier), nil
+		}
+		capa, err := s.intOpt("capa", 1)
+		if err != nil {
+			return nil, err
+		}
+		speed, err := s.intOpt("speed", 1)
+		if err != nil {
+			return nil, err
+		}
+		return NewConveyorWithSpeed(name("conv"), dir, capa, speed), nil
+	case "obstacle":
+		typ := ObstacleWall
+		if len(s.args) > 0 {
+			typ = ObstacleType(s.args[0])
+		}
+		return NewObstacle(name(string(typ)), typ), nil
+	case "generator":
+		power, err := s.floatOpt("power", 10)
+		if err != nil {
+			return nil, err
+		}
+		burn, err := s.intOpt("burn", 20)
+		if err != nil {
+			return nil, err
+		}
+		fuel, err := s.intOpt("fuel", 5)
+		if err != nil {
+			return nil, err
+		}
+		return NewGenerator(name("gen"), power, burn, fuel), nil
+	case "splitter":
+		dir, err := s.directionArg(0)
+		if err != nil {
+			return nil, err
+		}
+		outputs, err := s.intOpt("outputs", 2)
+		if err != nil {
+			return nil, err
+		}
+		if outputs != 2 && outputs != 3 {
+			return nil, fmt.Errorf("splitter: invalid outputs %d", outputs)
+		}
+		capa, err := s.intOpt("capa", 1)
+		if err != nil {
+			return nil, err
+		}
+		sp := NewSplitter(name("split"), dir, outputs, capa)
+		if ps, ok := s.opts["priority"]; ok {
+			prio, err := grid.ParseDirection(ps)
+			if err == nil {
+				err = sp.SetPriority(prio)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("splitter: invalid priority %q", ps)
+			}
+		}
+		return sp, nil
+	case "sorter":
+		dir, err := s.directionArg(0)
+		if err != nil {
+			return nil, err
+		}
+		var filter []Resource
+		if len(s.args) > 1 {
+			for _, a := range strings.Split(s.args[1], ",") {
+				res := Resource(a)
+				if !slices.Contains(AllResources(), res) {
+					return nil, fmt.Errorf("sorter: unknown resource %q", a)
+				}
+				filter = append(filter, res)
+			}
+		}
+		side := dir.Left()
+		if ss, ok := s.opts["side"]; ok {
+			side, err = grid.ParseDirection(ss)
+			if err != nil {
+				return nil, fmt.Errorf("sorter: invalid side %q", ss)
+			}
+		}
+		capa, err := s.intOpt("capa", 1)
+		if err != nil {
+			return nil, err
+		}
+		so, err := NewSorter(name("sort"), dir, side, filter, capa)
+		if err != nil {
+			return nil, fmt.Errorf("sorter: %w", err)
+		}
+		return so, nil
+	case "tunnel":
+		dir, err := s.directionArg(0)
+		if err != nil {
+			return nil, err
+		}
+		length, err := s.intOpt("length", TunnelMaxLength)
+		if err != nil {
+			return nil, err
+		}
+		capa, err := s.intOpt("capa", TunnelMaxLength)
+		if err != nil {
+			return nil, err
+		}
+		return NewTunnelEntrance(name("tunnel"), dir, length, capa), nil
+	case "tunnelexit":
+		dir, err := s.directionArg(0)
+		if err != nil {
+			return nil, err
+		}
+		return NewTunnelExit(name("tunnelexit"), dir), nil
+	case "inserter":
+		dir, err := s.directionArg(0)
+		if err != nil {
+			return nil, err
+		}
+		items, err := s.intOpt("items", 1)
+		if err != nil {
+			return nil, err
+		}
+		swing, err := s.intOpt("swing", 2)
+		if err != nil {
+			return nil, err
+		}
+		return NewInserter(name("ins"), dir, items, swing), nil
+	case "chest":
+		filter := None
+		if len(s.args) > 0 {
+			res, err := s.resourceArg(0)
+			if err != nil {
+				return nil, err
+			}
+			filter = res
+		}
+		capa, err := s.intOpt("capa", 16)
+		if err != nil {
+			return nil, err
+		}
+		return NewChest(name("chest"), capa, filter), nil
+	case "miner":
+		if _, err := s.resourceArg(0); err != nil {
+			return nil, err
+		}
+		rate, err := s.rateOpt("rate", NewRate(1, 2))
+		if err != nil {
+			return nil, err
+		}
+		stock, err := s.intOpt("stock", 2)
+		if err != nil {
+			return nil, err
+		}
+		size, err := s.sizeOpt("size", grid.S(1, 1))
+		if err != nil {
+			return nil, err
+		}
+		return NewMiner(name("miner"), size, rate, stock), nil
+	case "lab":
+		capa, err := s.intOpt("capa", 10)
+		if err != nil {
+			return nil, err
+		}
+		return NewLab(name("lab"), capa), nil
+	case "pole":
+		radius, err := s.intOpt("radius", 2)
+		if err != nil {
+			return nil, err
+		}
+		return NewPowerPole(name("pole"), radius), nil
+	default:
+		return nil, fmt.Errorf("unknown kind %q", s.kind)
+	}
+}
+
+// deposit returns the deposit described by deposit and miner specs.
+func (s textMapSpec) deposit() (Deposit, bool, error) {
+	if s.kind != "deposit" && s.kind != "miner" {
+		return Deposit{}, false, nil
+	}
+	res, err := s.resourceArg(0)
+	if err != nil {
+		return Deposit{}, false, err
+	}
+	amount, err := s.intOpt("amount", 100)
+	if err != nil {
+		return Deposit{}, false, err
+	}
+	return Deposit{Resource: res, Amount: amount}, true, nil
+}
+
+// ParseTextMap creates a universe from a text map.
+func ParseTextMap(r io.Reader) (*Universe, error) {
+	var rows [][]rune
+	legend := map[rune]textMapSpec{}
+	scanner := bufio.NewScanner(r)
+	inLegend := false
+	explicitIO := true
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if !inLegend {
+			if strings.TrimSpace(line) == "" {
+				inLegend = len(rows) > 0
+				continue
+			}
+			rows = append(rows, []rune(line))
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		cs, specStr, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(cs) == textMapIOKey {
+			switch mode := strings.TrimSpace(specStr); mode {
+			case textMapIODirect:
+				explicitIO = false
+			case textMapIOExplicit:
+				explicitIO = true
+			default:
+				return nil, fmt.Errorf("line %d: invalid io mode %q", lineNo, mode)
+			}
+			continue
+		}
+		cr := []rune(strings.TrimSpace(cs))
+		if !ok || len(cr) != 1 {
+			return nil, fmt.Errorf("line %d: invalid legend entry %q", lineNo, line)
+		}
+		c := cr[0]
+		if isTextMapBuiltin(c) {
+			return nil, fmt.Errorf("line %d: character %q is reserved", lineNo, c)
+		}
+		if _, ok := legend[c]; ok {
+			return nil, fmt.Errorf("line %d: character %q is already defined", lineNo, c)
+		}
+		spec, err := parseTextMapSpec(specStr)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		legend[c] = spec
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("map is empty")
+	}
+
+	size := grid.S(0, len(rows))
+	for _, row := range rows {
+		size.DX = Max(size.DX, len(row))
+	}
+	at := func(p grid.Position) rune {
+		if p.Y >= len(rows) || p.X >= len(rows[p.Y]) {
+			return textMapEmpty
+		}
+		return rows[p.Y][p.X]
+	}
+
+	u := NewUniverse(size)
+	u.SetExplicitIO(explicitIO)
+	for y, row := range rows {
+		for x, c := range row {
+			p := grid.P(x, y)
+			if _, occ := u.ObjectAt(p); occ {
+				// covered by a larger object
+				continue
+			}
+			var obj Object
+			var dep Deposit
+			var hasDep bool
+			switch {
+			case c == textMapEmpty || c == ' ':
+				continue
+			case textMapTerrain[c] != TerrainGround:
+				u.SetTerrain(p, textMapTerrain[c])
+				continue
+			case c == textMapWall:
+				obj = NewObstacle(fmt.Sprintf("wall_%d_%d", x, y), ObstacleWall)
+			case textMapConveyors[c] != grid.None:
+				obj = NewConveyor(fmt.Sprintf("conv_%d_%d", x, y), textMapConveyors[c], 1)
+			default:
+				spec, ok := legend[c]
+				if !ok {
+					return nil, fmt.Errorf("line %d: character %q is not defined", y+1, c)
+				}
+				var err error
+				dep, hasDep, err = spec.deposit()
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %q: %w", y+1, c, err)
+				}
+				if spec.kind == "deposit" {
+					u.SetDeposit(p, dep)
+					continue
+				}
+				obj, err = spec.newObject(p)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %q: %w", y+1, c, err)
+				}
+			}
+			r := grid.R(p, obj.Size())
+			for _, op := range r.Positions() {
+				if at(op) != c {
+					return nil, fmt.Errorf("line %d: %q: object of size %dx%d does not fit", y+1, c, r.DX, r.DY)
+				}
+				if hasDep {
+					u.SetDeposit(op, dep)
+				}
+			}
+			if err := u.AddObject(obj, p); err != nil {
+				return nil, fmt.Errorf("line %d: %q: %w", y+1, c, err)
+			}
+		}
+	}
+	return u, nil
+}
+
+func isTextMapBuiltin(c rune) bool {
+	_, isConv := textMapConveyors[c]
+	_, isTerrain := textMapTerrain[c]
+	return isConv || isTerrain || c == textMapEmpty || c == textMapWall || c == ' '
+}
+
+// textMapEntry returns the character of an object, or the legend entry if
+// it has no built-in character.
+func textMapEntry(obj Object) (rune, string, error) {
+	switch obj := obj.(type) {
+	case *Obstacle:
+		if obj.typ == ObstacleWall {
+			return textMapWall, "", nil
+		}
+		return 0, fmt.Sprintf("obstacle %s", obj.typ), nil
+	case *Conveyor:
+		if obj.capacity == 1 && obj.ticksPerTile == 1 {
+			for c, d := range textMapConveyors {
+				if d == obj.dir {
+					return c, "", nil
+				}
+			}
+		}
+		return 0, fmt.Sprintf("conveyor %s capa=%d speed=%d", obj.dir, obj.capacity, obj.ticksPerTile), nil
+	case *IncarnationProducer:
+		return 0, fmt.Sprintf("producer %s rate=%d/%d stock=%d", obj.resource, obj.rate.count, obj.rate.perTicks, obj.stock.capacity), nil
+	case *Assembler:
+		spec := fmt.Sprintf("assembler %s in=%d out=%d size=%dx%d speed=%g", obj.receipt.PrimaryOutput(), obj.inCapa, obj.outStock.capacity, obj.size.DX, obj.size.DY, obj.speed)
+		if obj.powerUsage > 0 {
+			spec += fmt.Sprintf(" power=%g", obj.powerUsage)
+		}
+		return 0, spec, nil
+	case *Generator:
+		return 0, fmt.Sprintf("generator power=%g burn=%d fuel=%d", obj.power, obj.burnTicks, obj.fuel.capacity), nil
+	case *Splitter:
+		spec := fmt.Sprintf("splitter %s outputs=%d capa=%d", obj.dir, len(obj.outputs), obj.capacity)
+		if obj.priority != grid.None {
+			spec += fmt.Sprintf(" priority=%s", obj.priority)
+		}
+		return 0, spec, nil
+	case *Sorter:
+		var filter []string
+		for _, res := range obj.filter {
+			filter = append(filter, string(res))
+		}
+		if len(filter) == 0 {
+			return 0, fmt.Sprintf("sorter %s side=%s capa=%d", obj.dir, obj.side, obj.capacity), nil
+		}
+		return 0, fmt.Sprintf("sorter %s %s side=%s capa=%d", obj.dir, strings.Join(filter, ","), obj.side, obj.capacity), nil
+	case *TunnelEntrance:
+		return 0, fmt.Sprintf("tunnel %s length=%d capa=%d", obj.dir, obj.maxLength, obj.capacity), nil
+	case *TunnelExit:
+		return 0, fmt.Sprintf("tunnelexit %s", obj.dir), nil
+	case *Inserter:
+		return 0, fmt.Sprintf("inserter %s items=%d swing=%d", obj.dir, obj.itemsPerSwing, obj.swingTicks), nil
+	case *Chest:
+		if obj.filter != None {
+			return 0, fmt.Sprintf("chest %s capa=%d", obj.filter, obj.stock.capacity), nil
+		}
+		return 0, fmt.Sprintf("chest capa=%d", obj.stock.capacity), nil
+	case *Miner:
+		for _, p := range obj.area.Positions() {
+			if d := obj.deposits.At(p); !d.Empty() {
+				return 0, fmt.Sprintf("miner %s amount=%d rate=%d/%d stock=%d size=%dx%d", d.Resource, d.Amount, obj.rate.count, obj.rate.perTicks, obj.stock.capacity, obj.size.DX, obj.size.DY), nil
+			}
+		}
+		return 0, "", fmt.Errorf("miner %q is depleted and has no text map representation", obj.Name())
+	case *Lab:
+		return 0, fmt.Sprintf("lab capa=%d", obj.stock.capacity), nil
+	case *PowerPole:
+		return 0, fmt.Sprintf("pole radius=%d", obj.radius), nil
+	case *Finalizer:
+		return 0, fmt.Sprintf("finalizer %s", obj.resource), nil
+	case *Trashbin:
+		return 0, "trashbin", nil
+	default:
+		return 0, "", fmt.Errorf("object %q of type %T has no text map representation", obj.Name(), obj)
+	}
+}
+
+// WriteTextMap writes the layout of the universe as text map. The state of
+// the objects is not written. Water, rock and deposits are written for free
+// tiles; a miner is written with the first deposit below it, other objects
+// without the terrain below them.
+func WriteTextMap(u *Universe, w io.Writer) error {
+	size := u.Size()
+	rows := make([][]rune, size.DY)
+	for y := range rows {
+		rows[y] = []rune(strings.Repeat(string(textMapEmpty), size.DX))
+	}
+	letters := []rune(textMapLetters)
+	legend := map[string]rune{}
+	type entry struct {
+		positions []grid.Position
+		c         rune
+		spec      string
+	}
+	var entries []entry
+	for _, pt := range u.AllTerrain() {
+		if _, occ := u.ObjectAt(pt.Position); occ {
+			continue
+		}
+		for c, t := range textMapTerrain {
+			if t == pt.Terrain {
+				entries = append(entries, entry{positions: []grid.Position{pt.Position}, c: c})
+			}
+		}
+	}
+	for _, pd := range u.AllDeposits() {
+		if _, occ := u.ObjectAt(pd.Position); occ {
+			continue
+		}
+		entries = append(entries, entry{
+			positions: []grid.Position{pd.Position},
+			spec:      fmt.Sprintf("deposit %s amount=%d", pd.Resource, pd.Amount),
+		})
+	}
+	for _, obj := range u.AllObjects() {
+		c, spec, err := textMapEntry(obj.Value)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{positions: obj.Positions(), c: c, spec: spec})
+	}
+	for _, e := range entries {
+		c, spec := e.c, e.spec
+		if c == 0 {
+			lc, ok := legend[spec]
+			if !ok {
+				if len(legend) >= len(letters) {
+					return fmt.Errorf("too many legend entries")
+				}
+				lc = letters[len(legend)]
+				legend[spec] = lc
+			}
+			c = lc
+		}
+		for _, p := range e.positions {
+			rows[p.Y][p.X] = c
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, row := range rows {
+		fmt.Fprintln(bw, string(row))
+	}
+	if len(legend) > 0 || !u.ExplicitIO() {
+		fmt.Fprintln(bw)
+	}
+	if !u.ExplicitIO() {
+		fmt.Fprintf(bw, "%s: %s\n", textMapIOKey, textMapIODirect)
+	}
+	if len(legend) > 0 {
+		specs := make([]string, 0, len(legend))
+		for spec := range legend {
+			specs = append(specs, spec)
+		}
+		sort.Slice(specs, func(i, j int) bool {
+			return strings.IndexRune(textMapLetters, legend[specs[i]]) < strings.IndexRune(textMapLetters, legend[specs[j]])
+		})
+		for _, spec := range specs {
+			fmt.Fprintf(bw, "%c: %s\n", legend[spec], spec)
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,74 @@
+package minifac
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testTextMap = `##########
+#P>>>>A>T#
+#C>>>>^..#
+#.....BB.#
+#I>>>>BB.#
+#....<<<F#
+##########
+
+io: direct
+A: assembler steel in=5 out=5 size=1x1
+B: assembler iron in=4 out=3 size=2x2
+C: producer coal rate=1/2 stock=2
+F: finalizer iron
+I: producer ironore rate=1/3 stock=4
+P: producer iron rate=1/2 stock=2
+T: trashbin
+`
+
+func TestTextMapRoundTrip(t *testing.T) {
+	u, err := ParseTextMap(strings.NewReader(testTextMap))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if have := len(u.AllObjects()); have != 54 {
+		t.Fatalf("objects: want %d, have %d", 54, have)
+	}
+
+	buf := &bytes.Buffer{}
+	err = WriteTextMap(u, buf)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	// letters are re-assigned in order of appearance, so the written map must
+	// be stable when parsed and written again
+	pu, err := ParseTextMap(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("parse written: %v\n%s", err, buf.String())
+	}
+	pbuf := &bytes.Buffer{}
+	err = WriteTextMap(pu, pbuf)
+	if err != nil {
+		t.Fatalf("write parsed: %v", err)
+	}
+	if buf.String() != pbuf.String() {
+		t.Fatalf("round trip differs:\nwant:\n%s\nhave:\n%s", buf.String(), pbuf.String())
+	}
+}
+
+func TestTextMapErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"P>\n",
+		"P>\n\nP: producer unobtainium\n",
+		"P>\n\nP: teleporter\n",
+		"P>\n\n>: trashbin\n",
+		"A.\n..\n\nA: assembler iron size=2x2\n",
+		"P>\n\nP: producer coal rate=1\n",
+		"M>\n\nM: miner\n",
+	}
+	for _, test := range tests {
+		_, err := ParseTextMap(strings.NewReader(test))
+		if err == nil {
+			t.Fatalf("parse %q: want error, have none", test)
+		}
+	}
+}
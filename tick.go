@@ -1,5 +1,10 @@
 package minifac
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 func NewRate(count, perTicks int) Rate {
 	return Rate{count: count, perTicks: perTicks}
 }
@@ -15,3 +20,25 @@ func (r Rate) Count(ticks int) int {
 	}
 	return r.count * ticks / r.perTicks
 }
+
+type rateJSON struct {
+	Count    int `json:"count"`
+	PerTicks int `json:"per_ticks"`
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rateJSON{Count: r.count, PerTicks: r.perTicks})
+}
+
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	var rj rateJSON
+	err := json.Unmarshal(data, &rj)
+	if err != nil {
+		return err
+	}
+	if rj.PerTicks <= 0 {
+		return fmt.Errorf("invalid rate %d/%d", rj.Count, rj.PerTicks)
+	}
+	*r = NewRate(rj.Count, rj.PerTicks)
+	return nil
+}
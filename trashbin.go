@@ -1,6 +1,7 @@
 package minifac
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/mazzegi/minifac/grid"
@@ -15,16 +16,15 @@ func NewTrashbin(name string) *Trashbin {
 }
 
 type Trashbin struct {
-	name  string
-	total int
+	name string
 }
 
 func (c *Trashbin) Size() grid.Size {
 	return grid.S(1, 1)
 }
 
-func (c *Trashbin) ConsumeAtPositions(base grid.Position) []grid.Position {
-	return []grid.Position{base}
+func (c *Trashbin) ConsumeAtPositions(r grid.Rectangle) []grid.Position {
+	return r.Positions()
 }
 
 func (c *Trashbin) Tick() {
@@ -38,12 +38,11 @@ func (c *Trashbin) Name() string {
 func (c *Trashbin) Info() []string {
 	return []string{
 		fmt.Sprintf("Trashbin: %s", c.name),
-		fmt.Sprintf("Consumed: %d", c.total),
 	}
 }
 
 func (c *Trashbin) ConsumeFrom(res Resource, dir grid.Direction) {
-	c.total++
+
 }
 
 func (c *Trashbin) CanConsumeFrom(Resource, grid.Direction) bool {
@@ -53,3 +52,29 @@ func (c *Trashbin) CanConsumeFrom(Resource, grid.Direction) bool {
 func (c *Trashbin) CanConsumeAny() bool {
 	return true
 }
+
+type trashbinJSON struct {
+	Name string `json:"name"`
+}
+
+func (c *Trashbin) Kind() ObjectKind {
+	return KindTrashbin
+}
+
+func (c *Trashbin) MarshalJSON() ([]byte, error) {
+	return json.Marshal(trashbinJSON{
+		Name: c.name,
+	})
+}
+
+func (c *Trashbin) UnmarshalJSON(data []byte) error {
+	var tj trashbinJSON
+	err := json.Unmarshal(data, &tj)
+	if err != nil {
+		return err
+	}
+	*c = Trashbin{
+		name: tj.Name,
+	}
+	return nil
+}
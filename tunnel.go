@@ -0,0 +1,332 @@
+package minifac
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+// TunnelMaxLength is the default maximum distance between the entrance and
+// the exit of a tunnel.
+const TunnelMaxLength = 5
+
+var _ Consumer = &TunnelEntrance{}
+var _ Producer = &TunnelExit{}
+var _ StatusReporter = &TunnelEntrance{}
+var _ StatusReporter = &TunnelExit{}
+
+// NewTunnelEntrance creates the entrance of a tunnel facing dir. It is
+// linked to the nearest unlinked exit facing the same direction within
+// maxLength tiles, when both are placed in a universe.
+func NewTunnelEntrance(name string, dir grid.Direction, maxLength int, capa int) *TunnelEntrance {
+	return &TunnelEntrance{
+		name:      name,
+		dir:       dir,
+		maxLength: maxLength,
+		capacity:  capa,
+		buffer:    NewQueue[*conveyorItem](),
+	}
+}
+
+// TunnelEntrance takes items from its back and carries them underground to
+// its exit, moving by one tile per tick.
+type TunnelEntrance struct {
+	name      string
+	dir       grid.Direction
+	maxLength int
+	capacity  int
+	buffer    *Queue[*conveyorItem]
+	waiting   int
+	exit      *TunnelExit
+	length    int // distance to the exit
+}
+
+func (t *TunnelEntrance) Size() grid.Size {
+	return grid.S(1, 1)
+}
+
+func (t *TunnelEntrance) Dir() grid.Direction {
+	return t.dir
+}
+
+func (t *TunnelEntrance) Name() string {
+	return t.name
+}
+
+// Exit returns the linked exit and its distance.
+func (t *TunnelEntrance) Exit() (*TunnelExit, int, bool) {
+	return t.exit, t.length, t.exit != nil
+}
+
+func (t *TunnelEntrance) Info() []string {
+	link := "unlinked"
+	if t.exit != nil {
+		link = fmt.Sprintf("%s (%d tiles)", t.exit.name, t.length)
+	}
+	return []string{
+		fmt.Sprintf("Tunnel entrance: %s", t.name),
+		fmt.Sprintf("Status: %s", t.Status()),
+		fmt.Sprintf("Exit  : %s", link),
+		fmt.Sprintf("Items : %d/%d", t.buffer.Len(), t.capacity),
+	}
+}
+
+func (t *TunnelEntrance) Tick() {
+	for _, item := range t.buffer.Values() {
+		if item.Progress < t.length {
+			item.Progress++
+		}
+	}
+	if t.frontReady() {
+		t.waiting++
+	}
+}
+
+func (t *TunnelEntrance) frontReady() bool {
+	item, ok := t.buffer.Peek()
+	return ok && t.exit != nil && item.Progress >= t.length
+}
+
+func (t *TunnelEntrance) Status() Status {
+	switch {
+	case t.buffer.Len() == 0:
+		return Status{Kind: StatusIdle}
+	case t.waiting > 0 || t.exit == nil:
+		return Status{Kind: StatusOutputBlocked}
+	default:
+		return Status{Kind: StatusWorking}
+	}
+}
+
+func (t *TunnelEntrance) ConsumeAtPositions(r grid.Rectangle) []grid.Position {
+	return []grid.Position{r.Position}
+}
+
+func (t *TunnelEntrance) ConsumeFrom(res Resource, dir grid.Direction) {
+	if !t.CanConsumeFrom(res, dir) {
+		return
+	}
+	t.buffer.Enqueue(&conveyorItem{Resource: res})
+}
+
+func (t *TunnelEntrance) CanConsumeFrom(res Resource, dir grid.Direction) bool {
+	return dir == t.dir.Opposite() && t.CanConsumeAny()
+}
+
+// CanConsumeAny reports whether the entrance takes items. Unlinked entrances
+// take none.
+func (t *TunnelEntrance) CanConsumeAny() bool {
+	return t.exit != nil && t.buffer.Len() < t.capacity
+}
+
+func NewTunnelExit(name string, dir grid.Direction) *TunnelExit {
+	return &TunnelExit{
+		name: name,
+		dir:  dir,
+	}
+}
+
+// TunnelExit hands out the items, which arrived at the end of the tunnel,
+// to the tile in front of it.
+type TunnelExit struct {
+	name     string
+	dir      grid.Direction
+	entrance *TunnelEntrance
+}
+
+func (t *TunnelExit) Size() grid.Size {
+	return grid.S(1, 1)
+}
+
+func (t *TunnelExit) Dir() grid.Direction {
+	return t.dir
+}
+
+func (t *TunnelExit) Name() string {
+	return t.name
+}
+
+func (t *TunnelExit) Linked() bool {
+	return t.entrance != nil
+}
+
+func (t *TunnelExit) Info() []string {
+	link := "unlinked"
+	if t.entrance != nil {
+		link = t.entrance.name
+	}
+	return []string{
+		fmt.Sprintf("Tunnel exit: %s", t.name),
+		fmt.Sprintf("Status  : %s", t.Status()),
+		fmt.Sprintf("Entrance: %s", link),
+	}
+}
+
+func (t *TunnelExit) Tick() {
+
+}
+
+func (t *TunnelExit) Status() Status {
+	if t.entrance == nil {
+		return Status{Kind: StatusIdle}
+	}
+	return t.entrance.Status()
+}
+
+func (t *TunnelExit) ProduceAtPositions(r grid.Rectangle) []grid.Position {
+	return []grid.Position{r.Position.Neighbour(t.dir)}
+}
+
+func (t *TunnelExit) CanProduce() bool {
+	return t.entrance != nil && t.entrance.frontReady()
+}
+
+func (t *TunnelExit) Produce() (Resource, bool) {
+	if !t.CanProduce() {
+		return None, false
+	}
+	item, _ := t.entrance.buffer.Dequeue()
+	t.entrance.waiting = 0
+	return item.Resource, true
+}
+
+func (t *TunnelExit) Resource() Resource {
+	if t.entrance == nil {
+		return None
+	}
+	item, ok := t.entrance.buffer.Peek()
+	if !ok {
+		return None
+	}
+	return item.Resource
+}
+
+// linkTunnel links a newly added tunnel entrance or exit to its nearest
+// unlinked counterpart facing the same direction.
+func (u *Universe) linkTunnel(obj *grid.Object[Object]) {
+	switch t := obj.Value.(type) {
+	case *TunnelEntrance:
+		pos := obj.Position
+		for l := 1; l <= t.maxLength; l++ {
+			pos = pos.Neighbour(t.dir)
+			other := u.grid.ObjectAt(pos)
+			if other == nil {
+				continue
+			}
+			if exit, ok := other.Value.(*TunnelExit); ok && exit.dir == t.dir && exit.entrance == nil {
+				t.exit, t.length = exit, l
+				exit.entrance = t
+				return
+			}
+		}
+	case *TunnelExit:
+		pos := obj.Position
+		for l := 1; ; l++ {
+			pos = pos.Neighbour(t.dir.Opposite())
+			if !u.grid.ContainsPosition(pos) {
+				return
+			}
+			other := u.grid.ObjectAt(pos)
+			if other == nil {
+				continue
+			}
+			if ent, ok := other.Value.(*TunnelEntrance); ok && ent.dir == t.dir && ent.exit == nil && l <= ent.maxLength {
+				ent.exit, ent.length = t, l
+				t.entrance = ent
+				return
+			}
+		}
+	}
+}
+
+// unlinkTunnel dissolves the link of a tunnel entrance or exit, which is
+// about to be deleted. Items in the tunnel stay in the entrance.
+func unlinkTunnel(obj Object) {
+	switch t := obj.(type) {
+	case *TunnelEntrance:
+		if t.exit != nil {
+			t.exit.entrance = nil
+			t.exit = nil
+		}
+	case *TunnelExit:
+		if t.entrance != nil {
+			t.entrance.exit = nil
+			t.entrance = nil
+		}
+	}
+}
+
+type tunnelEntranceJSON struct {
+	Name      string                `json:"name"`
+	Dir       grid.Direction        `json:"dir"`
+	MaxLength int                   `json:"max_length"`
+	Capacity  int                   `json:"capacity"`
+	Items     *Queue[*conveyorItem] `json:"items"`
+	Waiting   int                   `json:"waiting"`
+}
+
+func (t *TunnelEntrance) Kind() ObjectKind {
+	return KindTunnelEntrance
+}
+
+// MarshalJSON writes the entrance without its link, which is restored when
+// the entrance is placed in a universe.
+func (t *TunnelEntrance) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tunnelEntranceJSON{
+		Name:      t.name,
+		Dir:       t.dir,
+		MaxLength: t.maxLength,
+		Capacity:  t.capacity,
+		Items:     t.buffer,
+		Waiting:   t.waiting,
+	})
+}
+
+func (t *TunnelEntrance) UnmarshalJSON(data []byte) error {
+	var tj tunnelEntranceJSON
+	err := json.Unmarshal(data, &tj)
+	if err != nil {
+		return err
+	}
+	if tj.MaxLength <= 0 {
+		return fmt.Errorf("non-positive max length %d", tj.MaxLength)
+	}
+	*t = *NewTunnelEntrance(tj.Name, tj.Dir, tj.MaxLength, tj.Capacity)
+	if tj.Items != nil {
+		for _, item := range tj.Items.Values() {
+			if item == nil {
+				return fmt.Errorf("invalid item")
+			}
+		}
+		t.buffer = tj.Items
+	}
+	t.waiting = tj.Waiting
+	return nil
+}
+
+type tunnelExitJSON struct {
+	Name string         `json:"name"`
+	Dir  grid.Direction `json:"dir"`
+}
+
+func (t *TunnelExit) Kind() ObjectKind {
+	return KindTunnelExit
+}
+
+func (t *TunnelExit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tunnelExitJSON{
+		Name: t.name,
+		Dir:  t.dir,
+	})
+}
+
+func (t *TunnelExit) UnmarshalJSON(data []byte) error {
+	var tj tunnelExitJSON
+	err := json.Unmarshal(data, &tj)
+	if err != nil {
+		return err
+	}
+	*t = *NewTunnelExit(tj.Name, tj.Dir)
+	return nil
+}
@@ -0,0 +1,51 @@
+package minifac
+
+import (
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+func TestTunnel(t *testing.T) {
+	u := NewUniverse(grid.S(9, 1))
+	u.SetExplicitIO(false)
+	ent := NewTunnelEntrance("tunnel", grid.East, 5, 5)
+	exit := NewTunnelExit("tunnel_exit", grid.East)
+	u.AddObject(NewIncarnationProducer("prod", Coal, NewRate(1, 1), 1), grid.P(0, 0))
+	u.AddObject(NewConveyor("conv", grid.East, 1), grid.P(1, 0))
+	u.AddObject(ent, grid.P(2, 0))
+	for x := 3; x <= 5; x++ {
+		u.AddObject(NewObstacle("wall", ObstacleWall), grid.P(x, 0))
+	}
+	u.AddObject(NewFinalizer("fin", Coal), grid.P(7, 0))
+	if ent.CanConsumeAny() {
+		t.Fatalf("unlinked entrance takes items")
+	}
+	// the exit is linked when placed after the entrance
+	u.AddObject(exit, grid.P(6, 0))
+	if other, length, ok := ent.Exit(); !ok || other != exit || length != 4 {
+		t.Fatalf("link: want %q at %d, have %v at %d", exit.Name(), 4, other, length)
+	}
+
+	repeat(u.Tick, 30)
+	if consumed(u, "fin") == 0 {
+		t.Fatalf("no items arrived through the tunnel")
+	}
+
+	u.DeleteAt(grid.P(6, 0))
+	if _, _, ok := ent.Exit(); ok || exit.Linked() {
+		t.Fatalf("link survived deleting the exit")
+	}
+}
+
+func TestTunnelMaxLength(t *testing.T) {
+	u := NewUniverse(grid.S(9, 1))
+	u.SetExplicitIO(false)
+	ent := NewTunnelEntrance("tunnel", grid.East, 3, 3)
+	u.AddObject(ent, grid.P(0, 0))
+	u.AddObject(NewTunnelExit("far", grid.East), grid.P(4, 0))
+	u.AddObject(NewTunnelExit("west", grid.West), grid.P(2, 0))
+	if _, _, ok := ent.Exit(); ok {
+		t.Fatalf("linked to an exit out of range or facing another direction")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"image/jpeg"
 	"image/png"
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -22,6 +23,19 @@ func loadImageAsset(path string) (*ebiten.Image, error) {
 		return nil, fmt.Errorf("open %q: %w", path, err)
 	}
 	defer f.Close()
+	return decodeImage(f, path)
+}
+
+func loadImageFile(path string) (*ebiten.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", path, err)
+	}
+	defer f.Close()
+	return decodeImage(f, path)
+}
+
+func decodeImage(f io.Reader, path string) (*ebiten.Image, error) {
 	var decodeFnc func(io.Reader) (image.Image, error)
 	switch strings.ToLower(filepath.Ext(path)) {
 	case ".png":
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"github.com/mazzegi/minifac"
+	"github.com/mazzegi/minifac/grid"
+)
+
+// configureSelected changes the configuration of the selected object, if it
+// has one.
+func (ui *UI) configureSelected() {
+	if ui.selectedObject == nil {
+		return
+	}
+	obj, ok := ui.universe.ObjectAt(ui.selectedObject.Position)
+	if !ok || obj.Value != ui.selectedObject.Value {
+		// deleted in the meantime
+		ui.selectedObject = nil
+		return
+	}
+	switch obj := obj.Value.(type) {
+	case *minifac.Assembler:
+		obj.SetReceipt(nextReceipt(ui.universe, obj.Receipt()))
+	case *minifac.Conveyor:
+		obj.SetTier(nextConveyorTier(obj))
+	case *minifac.Splitter:
+		if err := obj.SetPriority(nextSplitterPriority(obj)); err != nil {
+			minifac.Log("ERROR: configure splitter: %v", err)
+		}
+	case *minifac.Sorter:
+		obj.SetFilter(nextSorterFilter(obj))
+	case *minifac.Chest:
+		obj.SetFilter(nextChestFilter(obj.Filter()))
+	}
+}
+
+// nextConveyorTier returns the tier following the one of c.
+func nextConveyorTier(c *minifac.Conveyor) minifac.ConveyorTier {
+	tiers := minifac.ConveyorTiers
+	if tier, ok := c.Tier(); ok {
+		for i, t := range tiers {
+			if t == tier {
+				return tiers[(i+1)%len(tiers)]
+			}
+		}
+	}
+	return tiers[0]
+}
+
+// nextReceipt returns the receipt following rec in the list of all receipts,
+// which are researched in u.
+func nextReceipt(u *minifac.Universe, rec minifac.Receipt) minifac.Receipt {
+	var recs []minifac.Receipt
+	for _, r := range minifac.AllReceipts() {
+		if u.ReceiptUnlocked(r.PrimaryOutput()) || r.PrimaryOutput() == rec.PrimaryOutput() {
+			recs = append(recs, r)
+		}
+	}
+	for i, r := range recs {
+		if r.PrimaryOutput() == rec.PrimaryOutput() {
+			return recs[(i+1)%len(recs)]
+		}
+	}
+	if len(recs) == 0 {
+		return rec
+	}
+	return recs[0]
+}
+
+// nextSplitterPriority cycles through no priority and all outputs of s.
+func nextSplitterPriority(s *minifac.Splitter) grid.Direction {
+	prios := append([]grid.Direction{grid.None}, s.Outputs()...)
+	for i, d := range prios {
+		if d == s.Priority() {
+			return prios[(i+1)%len(prios)]
+		}
+	}
+	return grid.None
+}
+
+// nextSorterFilter cycles through an empty filter and filters for each single
+// resource.
+func nextSorterFilter(s *minifac.Sorter) []minifac.Resource {
+	ress := minifac.AllResources()
+	filter := s.Filter()
+	if len(filter) == 0 {
+		return ress[:1]
+	}
+	for i, res := range ress {
+		if res == filter[0] && i+1 < len(ress) {
+			return ress[i+1 : i+2]
+		}
+	}
+	return nil
+}
+
+// nextChestFilter cycles through no filter and all resources.
+func nextChestFilter(filter minifac.Resource) minifac.Resource {
+	ress := append([]minifac.Resource{minifac.None}, minifac.AllResources()...)
+	for i, res := range ress {
+		if res == filter {
+			return ress[(i+1)%len(ress)]
+		}
+	}
+	return minifac.None
+}
@@ -18,6 +18,28 @@ type Widget interface {
 	SizeHint() SizeHint
 }
 
+// Hideable is implemented by widgets, which can be hidden. Layouts skip
+// hidden widgets.
+type Hideable interface {
+	Visible() bool
+}
+
+func visible(w Widget) bool {
+	h, ok := w.(Hideable)
+	return !ok || h.Visible()
+}
+
+// visibleWidgets filters the widgets, which are not hidden.
+func visibleWidgets(ws []Widget) []Widget {
+	var vws []Widget
+	for _, w := range ws {
+		if visible(w) {
+			vws = append(vws, w)
+		}
+	}
+	return vws
+}
+
 func NewForm(widget Widget, evts *EventHandler, font *truetype.Font) *Form {
 	f := &Form{
 		events: evts,
@@ -41,6 +63,14 @@ func (f *Form) Resize(x, y, width, height int) {
 	})
 }
 
+// Relayout resizes the widget to the current rect, e.g. after widgets have
+// been shown or hidden.
+func (f *Form) Relayout() {
+	f.widget.Resize(&ResizeContext{
+		Rect: f.rect,
+	})
+}
+
 func (f *Form) Draw(screen *ebiten.Image) {
 	f.widget.Draw(&DrawContext{
 		Screen: screen,
@@ -20,10 +20,11 @@ func NewImageButton(img *ebiten.Image, dx, dy int, evts *EventHandler) *ImageBut
 	scaledImg.DrawImage(img, opts)
 
 	b := &ImageButton{
-		events: evts,
-		img:    scaledImg,
-		dx:     dx,
-		dy:     dy,
+		events:  evts,
+		img:     scaledImg,
+		dx:      dx,
+		dy:      dy,
+		visible: true,
 	}
 	evts.OnMouseMove(func(p image.Point) {
 
@@ -32,20 +33,31 @@ func NewImageButton(img *ebiten.Image, dx, dy int, evts *EventHandler) *ImageBut
 }
 
 type ImageButton struct {
-	rect   image.Rectangle
-	events *EventHandler
-	img    *ebiten.Image
-	dx, dy int
+	rect    image.Rectangle
+	events  *EventHandler
+	img     *ebiten.Image
+	dx, dy  int
+	visible bool
 }
 
 func (b *ImageButton) OnClick(fn func()) {
 	b.events.OnMouseLeftClicked(func(p image.Point) {
-		if p.In(b.rect) {
+		if b.visible && p.In(b.rect) {
 			fn()
 		}
 	})
 }
 
+// SetVisible shows or hides the button. A hidden button is skipped by the
+// layouts and does not react to clicks.
+func (c *ImageButton) SetVisible(visible bool) {
+	c.visible = visible
+}
+
+func (c *ImageButton) Visible() bool {
+	return c.visible
+}
+
 func (c *ImageButton) SizeHint() SizeHint {
 	return SizeHint{
 		MaxHeight: c.dy,
@@ -122,9 +122,9 @@ func (c *VBoxLayout) SizeHint() SizeHint {
 	return c.styles.SizeHint
 }
 
-func (c *VBoxLayout) sizeHints() []SizeHint {
-	shs := make([]SizeHint, len(c.widgets))
-	for i, w := range c.widgets {
+func sizeHints(ws []Widget) []SizeHint {
+	shs := make([]SizeHint, len(ws))
+	for i, w := range ws {
 		shs[i] = w.SizeHint()
 	}
 	return shs
@@ -132,8 +132,9 @@ func (c *VBoxLayout) sizeHints() []SizeHint {
 
 func (c *VBoxLayout) Resize(ctx *ResizeContext) {
 	c.rect = ctx.Rect
-	rs := VSplitRectBySizeHints(c.rect, c.styles, c.sizeHints())
-	for i, w := range c.widgets {
+	ws := visibleWidgets(c.widgets)
+	rs := VSplitRectBySizeHints(c.rect, c.styles, sizeHints(ws))
+	for i, w := range ws {
 		wr := rs[i]
 		w.Resize(&ResizeContext{
 			Rect: wr,
@@ -142,7 +143,7 @@ func (c *VBoxLayout) Resize(ctx *ResizeContext) {
 }
 
 func (c *VBoxLayout) Draw(ctx *DrawContext) {
-	for _, w := range c.widgets {
+	for _, w := range visibleWidgets(c.widgets) {
 		w.Draw(ctx)
 	}
 }
@@ -165,18 +166,11 @@ func (c *HBoxLayout) SizeHint() SizeHint {
 	return c.styles.SizeHint
 }
 
-func (c *HBoxLayout) sizeHints() []SizeHint {
-	shs := make([]SizeHint, len(c.widgets))
-	for i, w := range c.widgets {
-		shs[i] = w.SizeHint()
-	}
-	return shs
-}
-
 func (c *HBoxLayout) Resize(ctx *ResizeContext) {
 	c.rect = ctx.Rect
-	rs := HSplitRectBySizeHints(c.rect, c.styles, c.sizeHints())
-	for i, w := range c.widgets {
+	ws := visibleWidgets(c.widgets)
+	rs := HSplitRectBySizeHints(c.rect, c.styles, sizeHints(ws))
+	for i, w := range ws {
 		wr := rs[i]
 		w.Resize(&ResizeContext{
 			Rect: wr,
@@ -185,7 +179,7 @@ func (c *HBoxLayout) Resize(ctx *ResizeContext) {
 }
 
 func (c *HBoxLayout) Draw(ctx *DrawContext) {
-	for _, w := range c.widgets {
+	for _, w := range visibleWidgets(c.widgets) {
 		w.Draw(ctx)
 	}
 }
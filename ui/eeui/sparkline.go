@@ -0,0 +1,65 @@
+package eeui
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var SparklineColor = color.RGBA{0, 200, 0, 255}
+
+func NewSparkline(evts *EventHandler) *Sparkline {
+	s := &Sparkline{
+		dataFunc: func() []float64 { return []float64{} },
+	}
+	return s
+}
+
+// Sparkline draws a series of non-negative values as bars, scaled to the
+// largest value.
+type Sparkline struct {
+	dataFunc func() []float64
+	rect     image.Rectangle
+}
+
+func (s *Sparkline) ChangeDataFunc(fn func() []float64) {
+	s.dataFunc = fn
+}
+
+func (c *Sparkline) SizeHint() SizeHint {
+	return SizeHint{
+		MaxHeight: 48,
+	}
+}
+
+func (c *Sparkline) Resize(ctx *ResizeContext) {
+	c.rect = ctx.Rect
+}
+
+func (c *Sparkline) Draw(ctx *DrawContext) {
+	screen := ctx.Screen
+	r := c.rect
+	vector.DrawFilledRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), color.Black, true)
+
+	values := c.dataFunc()
+	if len(values) == 0 {
+		return
+	}
+	var max float64
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	if max <= 0 {
+		return
+	}
+	barWidth := float32(r.Dx()) / float32(len(values))
+	for i, v := range values {
+		h := float32(v/max) * float32(r.Dy()-2)
+		x := float32(r.Min.X) + float32(i)*barWidth
+		y := float32(r.Max.Y) - 1 - h
+		vector.DrawFilledRect(screen, x, y, barWidth, h, SparklineColor, false)
+	}
+}
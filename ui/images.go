@@ -20,15 +20,29 @@ const (
 	ImageTypeConveyor_north ImageType = "conveyor_north.png"
 	ImageTypeConveyor_south ImageType = "conveyor_south.png"
 	ImageTypeConveyor_west  ImageType = "conveyor_west.png"
-	ImageTypeWood           ImageType = "wood.png"
-	ImageTypeCoal           ImageType = "coal.png"
-	ImageTypeStone          ImageType = "stone.png"
-	ImageTypeIronOre        ImageType = "ironore.png"
-	ImageTypeIron           ImageType = "iron.png"
-	ImageTypeSteel          ImageType = "steel.png"
 	ImageTypeWall           ImageType = "wall.png"
+	ImageTypeGenerator      ImageType = "generator.png"
+	ImageTypePowerPole      ImageType = "pole.png"
+	// ImageTypeSplitter is the palette item of splitters, which are placed
+	// facing the selected direction
+	ImageTypeSplitter  ImageType = "splitter_east.png"
+	ImageTypeSorter    ImageType = "sorter_east.png"
+	ImageTypeTunnelIn  ImageType = "tunnel_in_east.png"
+	ImageTypeTunnelOut ImageType = "tunnel_out_east.png"
+	ImageTypeInserter  ImageType = "inserter_east.png"
+	ImageTypeChest     ImageType = "chest.png"
+	ImageTypeMiner     ImageType = "miner.png"
+	ImageTypeLab       ImageType = "lab.png"
 )
 
+// terrainImageTypes are the textures of all terrains but ground, which is
+// the background.
+var terrainImageTypes = map[minifac.Terrain]ImageType{
+	minifac.TerrainWater: "water.png",
+	minifac.TerrainOre:   "ore.png",
+	minifac.TerrainRock:  "rock.png",
+}
+
 var allImageTypes = []ImageType{
 	ImageTypeProducer,
 	ImageTypeAssembler,
@@ -38,37 +52,38 @@ var allImageTypes = []ImageType{
 	ImageTypeConveyor_north,
 	ImageTypeConveyor_south,
 	ImageTypeConveyor_west,
-	ImageTypeWood,
-	ImageTypeCoal,
-	ImageTypeStone,
-	ImageTypeIronOre,
-	ImageTypeIron,
-	ImageTypeSteel,
 	ImageTypeWall,
+	ImageTypeGenerator,
+	ImageTypePowerPole,
+	ImageTypeSplitter,
+	ImageTypeSorter,
+	ImageTypeTunnelIn,
+	ImageTypeTunnelOut,
+	ImageTypeInserter,
+	ImageTypeChest,
+	ImageTypeMiner,
+	ImageTypeLab,
+}
+
+// directedImageType returns the image type of an object facing dir, like
+// "splitter_north.png".
+func directedImageType(base string, dir grid.Direction) ImageType {
+	return ImageType(fmt.Sprintf("%s_%s.png", base, dir))
 }
 
+// resourceImageType returns the image type of the icon of a registered
+// resource. Icons are loaded on first use.
 func resourceImageType(res minifac.Resource) ImageType {
-	switch res {
-	case minifac.Wood:
-		return ImageTypeWood
-	case minifac.Stone:
-		return ImageTypeStone
-	case minifac.Coal:
-		return ImageTypeCoal
-	case minifac.IronOre:
-		return ImageTypeIronOre
-	case minifac.Iron:
-		return ImageTypeIron
-	case minifac.Steel:
-		return ImageTypeSteel
-	default:
+	info, ok := minifac.LookupResource(res)
+	if !ok {
 		return ""
 	}
+	return ImageType(info.Icon)
 }
 
 type PositionedImage struct {
-	Position grid.Position
-	Image    *ebiten.Image
+	Rectangle grid.Rectangle
+	Image     *ebiten.Image
 }
 
 func mustLoadImage(typ ImageType) *ebiten.Image {
@@ -76,6 +91,16 @@ func mustLoadImage(typ ImageType) *ebiten.Image {
 	return mustLoadImageAsset(path)
 }
 
+// loadImage loads an image from the embedded assets and falls back to the
+// file system.
+func loadImage(typ ImageType) (*ebiten.Image, error) {
+	img, err := loadImageAsset(filepath.Join("assets", string(typ)))
+	if err == nil {
+		return img, nil
+	}
+	return loadImageFile(string(typ))
+}
+
 func NewImageHandler(u *minifac.Universe) *ImageHandler {
 	ih := &ImageHandler{
 		universe:          u,
@@ -102,6 +127,24 @@ type ImageHandler struct {
 	thumbnailOverlays map[imageOverlay]*ebiten.Image
 }
 
+// image returns the image of the given type and loads it, if it is not
+// loaded yet. It returns nil, if the image cannot be loaded.
+func (h *ImageHandler) image(typ ImageType) *ebiten.Image {
+	if img, ok := h.images[typ]; ok {
+		return img
+	}
+	if typ == "" {
+		return nil
+	}
+	img, err := loadImage(typ)
+	if err != nil {
+		minifac.Log("ERROR: load image %q: %v", typ, err)
+	}
+	// also cache failures, so they are logged only once
+	h.images[typ] = img
+	return img
+}
+
 func (h *ImageHandler) Images() []*PositionedImage {
 	//TODO: cache images
 	imgs := []*PositionedImage{}
@@ -109,31 +152,85 @@ func (h *ImageHandler) Images() []*PositionedImage {
 		switch obj := gobj.Value.(type) {
 		case *minifac.IncarnationProducer:
 			imgs = append(imgs, &PositionedImage{
-				Position: gobj.Position,
-				Image:    h.createThumbnailOverlay(ImageTypeProducer, resourceImageType(obj.Resource())),
+				Rectangle: gobj.Rectangle,
+				Image:     h.createThumbnailOverlay(ImageTypeProducer, resourceImageType(obj.Resource())),
 			})
 		case *minifac.Trashbin:
 			imgs = append(imgs, &PositionedImage{
-				Position: gobj.Position,
-				Image:    h.images[ImageTypeTrash],
+				Rectangle: gobj.Rectangle,
+				Image:     h.images[ImageTypeTrash],
 			})
 		case *minifac.Obstacle:
 			switch obj.Type() {
 			default:
 				imgs = append(imgs, &PositionedImage{
-					Position: gobj.Position,
-					Image:    h.images[ImageTypeWall],
+					Rectangle: gobj.Rectangle,
+					Image:     h.images[ImageTypeWall],
 				})
 			}
 		case *minifac.Finalizer:
 			imgs = append(imgs, &PositionedImage{
-				Position: gobj.Position,
-				Image:    h.createThumbnailOverlay(ImageTypeFinalizer, resourceImageType(obj.Resource())),
+				Rectangle: gobj.Rectangle,
+				Image:     h.createThumbnailOverlay(ImageTypeFinalizer, resourceImageType(obj.Resource())),
 			})
 		case *minifac.Assembler:
 			imgs = append(imgs, &PositionedImage{
-				Position: gobj.Position,
-				Image:    h.createThumbnailOverlay(ImageTypeAssembler, resourceImageType(obj.Resource())),
+				Rectangle: gobj.Rectangle,
+				Image:     h.createThumbnailOverlay(ImageTypeAssembler, resourceImageType(obj.Receipt().PrimaryOutput())),
+			})
+		case *minifac.Generator:
+			imgs = append(imgs, &PositionedImage{
+				Rectangle: gobj.Rectangle,
+				Image:     h.images[ImageTypeGenerator],
+			})
+		case *minifac.Splitter:
+			imgs = append(imgs, &PositionedImage{
+				Rectangle: gobj.Rectangle,
+				Image:     h.createOverlay(directedImageType("splitter", obj.Dir()), resourceImageType(obj.Resource())),
+			})
+		case *minifac.Sorter:
+			res := minifac.None
+			if filter := obj.Filter(); len(filter) > 0 {
+				res = filter[0]
+			}
+			imgs = append(imgs, &PositionedImage{
+				Rectangle: gobj.Rectangle,
+				Image:     h.createThumbnailOverlay(directedImageType("sorter", obj.Dir()), resourceImageType(res)),
+			})
+		case *minifac.TunnelEntrance:
+			imgs = append(imgs, &PositionedImage{
+				Rectangle: gobj.Rectangle,
+				Image:     h.image(directedImageType("tunnel_in", obj.Dir())),
+			})
+		case *minifac.TunnelExit:
+			imgs = append(imgs, &PositionedImage{
+				Rectangle: gobj.Rectangle,
+				Image:     h.createOverlay(directedImageType("tunnel_out", obj.Dir()), resourceImageType(obj.Resource())),
+			})
+		case *minifac.Inserter:
+			imgs = append(imgs, &PositionedImage{
+				Rectangle: gobj.Rectangle,
+				Image:     h.createThumbnailOverlay(directedImageType("inserter", obj.Dir()), resourceImageType(obj.Resource())),
+			})
+		case *minifac.Chest:
+			imgs = append(imgs, &PositionedImage{
+				Rectangle: gobj.Rectangle,
+				Image:     h.createThumbnailOverlay(ImageTypeChest, resourceImageType(obj.Filter())),
+			})
+		case *minifac.Miner:
+			imgs = append(imgs, &PositionedImage{
+				Rectangle: gobj.Rectangle,
+				Image:     h.createThumbnailOverlay(ImageTypeMiner, resourceImageType(obj.Resource())),
+			})
+		case *minifac.Lab:
+			imgs = append(imgs, &PositionedImage{
+				Rectangle: gobj.Rectangle,
+				Image:     h.images[ImageTypeLab],
+			})
+		case *minifac.PowerPole:
+			imgs = append(imgs, &PositionedImage{
+				Rectangle: gobj.Rectangle,
+				Image:     h.images[ImageTypePowerPole],
 			})
 		case *minifac.Conveyor:
 			var convType ImageType
@@ -151,8 +248,8 @@ func (h *ImageHandler) Images() []*PositionedImage {
 			}
 			img := h.createOverlay(convType, resourceImageType(obj.Resource()))
 			imgs = append(imgs, &PositionedImage{
-				Position: gobj.Position,
-				Image:    img,
+				Rectangle: gobj.Rectangle,
+				Image:     img,
 			})
 		default:
 			panic(fmt.Errorf("unknown object type %T", obj))
@@ -162,14 +259,48 @@ func (h *ImageHandler) Images() []*PositionedImage {
 	return imgs
 }
 
+// TerrainImages returns the textures of all tiles, which are not ground. They
+// are drawn below deposits and objects.
+func (h *ImageHandler) TerrainImages() []*PositionedImage {
+	var imgs []*PositionedImage
+	for _, pt := range h.universe.AllTerrain() {
+		img := h.image(terrainImageTypes[pt.Terrain])
+		if img == nil {
+			continue
+		}
+		imgs = append(imgs, &PositionedImage{
+			Rectangle: grid.R(pt.Position, grid.S(1, 1)),
+			Image:     img,
+		})
+	}
+	return imgs
+}
+
+// DepositImages returns the resource icons of all deposits, which are drawn
+// below the objects.
+func (h *ImageHandler) DepositImages() []*PositionedImage {
+	var imgs []*PositionedImage
+	for _, pd := range h.universe.AllDeposits() {
+		img := h.image(resourceImageType(pd.Resource))
+		if img == nil {
+			continue
+		}
+		imgs = append(imgs, &PositionedImage{
+			Rectangle: grid.R(pd.Position, grid.S(1, 1)),
+			Image:     img,
+		})
+	}
+	return imgs
+}
+
 func (h *ImageHandler) createOverlay(baseType ImageType, overlayType ImageType) *ebiten.Image {
 	if img, ok := h.overlays[imageOverlay{baseType, overlayType}]; ok {
 		return img
 	}
 
-	base := h.images[baseType]
-	overlay, ok := h.images[overlayType]
-	if !ok {
+	base := h.image(baseType)
+	overlay := h.image(overlayType)
+	if overlay == nil {
 		return base
 	}
 	img := ebiten.NewImageFromImage(base)
@@ -188,8 +319,11 @@ func (h *ImageHandler) createThumbnailOverlay(baseType ImageType, overlayType Im
 	if img, ok := h.thumbnailOverlays[imageOverlay{baseType, overlayType}]; ok {
 		return img
 	}
-	base := h.images[baseType]
-	overlay := h.images[overlayType]
+	base := h.image(baseType)
+	overlay := h.image(overlayType)
+	if overlay == nil {
+		return base
+	}
 
 	img := ebiten.NewImageFromImage(base)
 	baseBounds := base.Bounds()
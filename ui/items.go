@@ -7,28 +7,88 @@ import (
 	"github.com/mazzegi/minifac/grid"
 )
 
-func CreateObject(ty ImageType, res minifac.Resource) (minifac.Object, error) {
+// itemKinds are the object kinds created by the palette items.
+var itemKinds = map[ImageType]minifac.ObjectKind{
+	ImageTypeConveyor_east:  minifac.KindConveyor,
+	ImageTypeConveyor_south: minifac.KindConveyor,
+	ImageTypeConveyor_west:  minifac.KindConveyor,
+	ImageTypeConveyor_north: minifac.KindConveyor,
+	ImageTypeProducer:       minifac.KindIncarnationProducer,
+	ImageTypeAssembler:      minifac.KindAssembler,
+	ImageTypeTrash:          minifac.KindTrashbin,
+	ImageTypeGenerator:      minifac.KindGenerator,
+	ImageTypePowerPole:      minifac.KindPowerPole,
+	ImageTypeSplitter:       minifac.KindSplitter,
+	ImageTypeSorter:         minifac.KindSorter,
+	ImageTypeTunnelIn:       minifac.KindTunnelEntrance,
+	ImageTypeTunnelOut:      minifac.KindTunnelExit,
+	ImageTypeInserter:       minifac.KindInserter,
+	ImageTypeChest:          minifac.KindChest,
+	ImageTypeMiner:          minifac.KindMiner,
+	ImageTypeLab:            minifac.KindLab,
+	ImageTypeFinalizer:      minifac.KindFinalizer,
+}
+
+// itemUnlocked reports whether the palette item is researched in u.
+// Assemblers additionally need the receipt for res to be researched.
+func itemUnlocked(u *minifac.Universe, ty ImageType, res minifac.Resource) bool {
+	if kind, ok := itemKinds[ty]; ok && !u.ObjectUnlocked(kind) {
+		return false
+	}
+	if ty == ImageTypeAssembler {
+		return u.ReceiptUnlocked(res)
+	}
+	return true
+}
+
+// CreateObject creates the object for a palette item. Its name is made
+// unique by the position it will be placed at. Objects without a fixed
+// direction are placed facing dir.
+func CreateObject(ty ImageType, res minifac.Resource, dir grid.Direction, pos grid.Position) (minifac.Object, error) {
+	name := func(prefix string) string {
+		return fmt.Sprintf("%s_%d_%d", prefix, pos.X, pos.Y)
+	}
 	switch ty {
 	case ImageTypeConveyor_east:
-		return minifac.NewConveyor("conv", grid.East, 1), nil
+		return minifac.NewConveyor(name("conv"), grid.East, 1), nil
 	case ImageTypeConveyor_south:
-		return minifac.NewConveyor("conv", grid.South, 1), nil
+		return minifac.NewConveyor(name("conv"), grid.South, 1), nil
 	case ImageTypeConveyor_west:
-		return minifac.NewConveyor("conv", grid.West, 1), nil
+		return minifac.NewConveyor(name("conv"), grid.West, 1), nil
 	case ImageTypeConveyor_north:
-		return minifac.NewConveyor("conv", grid.North, 1), nil
+		return minifac.NewConveyor(name("conv"), grid.North, 1), nil
 	case ImageTypeProducer:
-		return minifac.NewIncarnationProducer(string(res)+"_prod", res, minifac.NewRate(1, 2), 2), nil
+		return minifac.NewIncarnationProducer(name("prod_"+string(res)), res, minifac.NewRate(1, 2), 2), nil
 	case ImageTypeAssembler:
 		rec, ok := minifac.ReceiptFor(res)
 		if !ok {
 			return nil, fmt.Errorf("no receipt for %q", res)
 		}
-		return minifac.NewAssembler(string(res)+"_ass", rec, 5, 5), nil
+		return minifac.NewAssembler(name("ass_"+string(res)), rec, 5, 5), nil
 	case ImageTypeTrash:
-		return minifac.NewTrashbin("trash"), nil
+		return minifac.NewTrashbin(name("trash")), nil
+	case ImageTypeGenerator:
+		return minifac.NewGenerator(name("gen"), 10, 20, 5), nil
+	case ImageTypePowerPole:
+		return minifac.NewPowerPole(name("pole"), 2), nil
+	case ImageTypeSplitter:
+		return minifac.NewSplitter(name("split"), dir, 2, 1), nil
+	case ImageTypeSorter:
+		return minifac.NewSorter(name("sort"), dir, dir.Left(), nil, 1)
+	case ImageTypeTunnelIn:
+		return minifac.NewTunnelEntrance(name("tunnel"), dir, minifac.TunnelMaxLength, minifac.TunnelMaxLength), nil
+	case ImageTypeTunnelOut:
+		return minifac.NewTunnelExit(name("tunnelexit"), dir), nil
+	case ImageTypeInserter:
+		return minifac.NewInserter(name("ins"), dir, 1, 2), nil
+	case ImageTypeChest:
+		return minifac.NewChest(name("chest"), 16, minifac.None), nil
+	case ImageTypeMiner:
+		return minifac.NewMiner(name("miner"), grid.S(1, 1), minifac.NewRate(1, 2), 2), nil
+	case ImageTypeLab:
+		return minifac.NewLab(name("lab"), 10), nil
 	case ImageTypeFinalizer:
-		return minifac.NewFinalizer("finalizer", res), nil
+		return minifac.NewFinalizer(name("fin_"+string(res)), res), nil
 	default:
 		return nil, fmt.Errorf("invalid item type %q", ty)
 	}
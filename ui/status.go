@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/mazzegi/minifac"
+)
+
+var statusColors = map[minifac.StatusKind]color.RGBA{
+	minifac.StatusIdle:          {128, 128, 128, 255},
+	minifac.StatusWorking:       {0, 200, 0, 255},
+	minifac.StatusInputStarved:  {240, 200, 0, 255},
+	minifac.StatusOutputBlocked: {220, 0, 0, 255},
+}
+
+// drawStatusBadges draws a small colored circle in the top right corner of
+// each object, which reports its status.
+func (ui *UI) drawStatusBadges(screen *ebiten.Image) {
+	radius := float32(math.Min(ui.scaleX, ui.scaleY) / 10)
+	for _, gobj := range ui.universe.AllObjects() {
+		sr, ok := gobj.Value.(minifac.StatusReporter)
+		if !ok {
+			continue
+		}
+		col, ok := statusColors[sr.Status().Kind]
+		if !ok {
+			continue
+		}
+		r := gobj.Rectangle
+		cx := float32(ui.scaleX*float64(r.X+r.DX)) - 2*radius
+		cy := float32(ui.scaleY*float64(r.Y)) + 2*radius
+		vector.DrawFilledCircle(screen, cx, cy, radius, color.Black, true)
+		vector.DrawFilledCircle(screen, cx, cy, radius*0.75, col, true)
+	}
+}
@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/mazzegi/minifac"
+	"github.com/mazzegi/minifac/grid"
+)
+
+var tunnelLinkColor = color.RGBA{255, 160, 0, 255}
+
+// drawTunnelLinks draws a line from each linked tunnel entrance to its exit.
+func (ui *UI) drawTunnelLinks(screen *ebiten.Image) {
+	center := func(p grid.Position) (float32, float32) {
+		return float32(ui.scaleX * (float64(p.X) + 0.5)), float32(ui.scaleY * (float64(p.Y) + 0.5))
+	}
+	for _, gobj := range ui.universe.AllObjects() {
+		ent, ok := gobj.Value.(*minifac.TunnelEntrance)
+		if !ok {
+			continue
+		}
+		_, length, ok := ent.Exit()
+		if !ok {
+			continue
+		}
+		to := gobj.Position
+		for i := 0; i < length; i++ {
+			to = to.Neighbour(ent.Dir())
+		}
+		x0, y0 := center(gobj.Position)
+		x1, y1 := center(to)
+		vector.StrokeLine(screen, x0, y0, x1, y1, 3, tunnelLinkColor, true)
+	}
+}
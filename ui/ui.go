@@ -24,10 +24,19 @@ func New(uni *minifac.Universe) *UI {
 		imageHandler: NewImageHandler(uni),
 		ticker:       time.NewTicker(500 * time.Millisecond),
 		running:      false,
+		selectedDir:  grid.East,
 	}
 	ui.ticker.Stop()
 
 	infoBox := eeui.NewTextBox(evts)
+	scenarioBox := eeui.NewTextBox(evts)
+	scenarioBox.ChangeTextFunc(func() []string {
+		if ui.scenario == nil {
+			return nil
+		}
+		return ui.scenario.Info()
+	})
+	sparkline := eeui.NewSparkline(evts)
 	selectItem := func(ty ImageType, res minifac.Resource) {
 		ui.selectedItem = ty
 		ui.selectedResource = res
@@ -35,11 +44,16 @@ func New(uni *minifac.Universe) *UI {
 			return []string{
 				"Selected:",
 				fmt.Sprintf("Item    : %s", ty),
-				fmt.Sprintf("Resource: %s", res),
+				fmt.Sprintf("Resource: %s", res.DisplayName()),
 			}
 		})
 	}
 
+	// track palette buttons to show only researched items
+	paletteItem := func(btn *eeui.ImageButton, ty ImageType, res minifac.Resource) {
+		ui.palette = append(ui.palette, paletteButton{button: btn, item: ty, resource: res})
+	}
+
 	baseTickerTime := 500 * time.Millisecond
 	tickerTime := baseTickerTime
 	resetTicker := func(d time.Duration) {
@@ -105,18 +119,22 @@ func New(uni *minifac.Universe) *UI {
 	btnConvEast.OnClick(func() {
 		selectItem(ImageTypeConveyor_east, minifac.None)
 	})
+	paletteItem(btnConvEast, ImageTypeConveyor_east, minifac.None)
 	btnConvSouth := eeui.NewImageButton(mustLoadImage(ImageTypeConveyor_south), 48, 48, evts)
 	btnConvSouth.OnClick(func() {
 		selectItem(ImageTypeConveyor_south, minifac.None)
 	})
+	paletteItem(btnConvSouth, ImageTypeConveyor_south, minifac.None)
 	btnConvWest := eeui.NewImageButton(mustLoadImage(ImageTypeConveyor_west), 48, 48, evts)
 	btnConvWest.OnClick(func() {
 		selectItem(ImageTypeConveyor_west, minifac.None)
 	})
+	paletteItem(btnConvWest, ImageTypeConveyor_west, minifac.None)
 	btnConvNorth := eeui.NewImageButton(mustLoadImage(ImageTypeConveyor_north), 48, 48, evts)
 	btnConvNorth.OnClick(func() {
 		selectItem(ImageTypeConveyor_north, minifac.None)
 	})
+	paletteItem(btnConvNorth, ImageTypeConveyor_north, minifac.None)
 	convLayout := eeui.NewHBoxLayout(
 		eeui.BoxLayoutStyles{
 			Padding: 4,
@@ -139,6 +157,7 @@ func New(uni *minifac.Universe) *UI {
 		btn.OnClick(func() {
 			selectItem(ImageTypeProducer, bres)
 		})
+		paletteItem(btn, ImageTypeProducer, bres)
 		prodBtns = append(prodBtns, btn)
 	}
 	prodLayout := eeui.NewHBoxLayout(
@@ -156,10 +175,11 @@ func New(uni *minifac.Universe) *UI {
 	var assBtns []eeui.Widget
 	for _, rec := range minifac.AllReceipts() {
 		rec := rec
-		btn := eeui.NewImageButton(ui.imageHandler.createThumbnailOverlay(ImageTypeAssembler, resourceImageType(rec.Output)), 48, 48, evts)
+		btn := eeui.NewImageButton(ui.imageHandler.createThumbnailOverlay(ImageTypeAssembler, resourceImageType(rec.PrimaryOutput())), 48, 48, evts)
 		btn.OnClick(func() {
-			selectItem(ImageTypeAssembler, rec.Output)
+			selectItem(ImageTypeAssembler, rec.PrimaryOutput())
 		})
+		paletteItem(btn, ImageTypeAssembler, rec.PrimaryOutput())
 		assBtns = append(assBtns, btn)
 	}
 	assLayout := eeui.NewHBoxLayout(
@@ -175,11 +195,13 @@ func New(uni *minifac.Universe) *UI {
 
 	//Finalizers
 	var finBtns []eeui.Widget
-	for _, res := range []minifac.Resource{minifac.Steel} {
+	for _, res := range minifac.ResourcesOfCategory(minifac.CategoryFinal) {
+		res := res
 		btn := eeui.NewImageButton(ui.imageHandler.createThumbnailOverlay(ImageTypeFinalizer, resourceImageType(res)), 48, 48, evts)
 		btn.OnClick(func() {
 			selectItem(ImageTypeFinalizer, res)
 		})
+		paletteItem(btn, ImageTypeFinalizer, res)
 		finBtns = append(finBtns, btn)
 	}
 	finLayout := eeui.NewHBoxLayout(
@@ -195,11 +217,13 @@ func New(uni *minifac.Universe) *UI {
 
 	//Misc
 	var miscBtns []eeui.Widget
-	{
-		btn := eeui.NewImageButton(ui.imageHandler.images[ImageTypeTrash], 48, 48, evts)
+	for _, ty := range []ImageType{ImageTypeTrash, ImageTypeGenerator, ImageTypePowerPole, ImageTypeSplitter, ImageTypeSorter, ImageTypeTunnelIn, ImageTypeTunnelOut, ImageTypeInserter, ImageTypeChest, ImageTypeMiner, ImageTypeLab} {
+		ty := ty
+		btn := eeui.NewImageButton(ui.imageHandler.images[ty], 48, 48, evts)
 		btn.OnClick(func() {
-			selectItem(ImageTypeTrash, minifac.None)
+			selectItem(ty, minifac.None)
 		})
+		paletteItem(btn, ty, minifac.None)
 		miscBtns = append(miscBtns, btn)
 	}
 	miscLayout := eeui.NewHBoxLayout(
@@ -213,6 +237,72 @@ func New(uni *minifac.Universe) *UI {
 		miscBtns...,
 	)
 
+	btnConfigure := eeui.NewButton("Configure", evts)
+	btnConfigure.OnClick(func() {
+		ui.configureSelected()
+	})
+	btnRotate := eeui.NewButton("Rotate", evts)
+	btnRotate.OnClick(func() {
+		ui.selectedDir = ui.selectedDir.Right()
+		infoBox.ChangeTextFunc(func() []string {
+			return []string{
+				fmt.Sprintf("Direction: %s", ui.selectedDir),
+			}
+		})
+	})
+	explicitIOText := func() string {
+		if ui.universe.ExplicitIO() {
+			return "Explicit I/O: on"
+		}
+		return "Explicit I/O: off"
+	}
+	btnExplicitIO := eeui.NewButton(explicitIOText(), evts)
+	btnExplicitIO.OnClick(func() {
+		ui.universe.SetExplicitIO(!ui.universe.ExplicitIO())
+		btnExplicitIO.ChangeText(explicitIOText())
+	})
+	configLayout := eeui.NewHBoxLayout(
+		eeui.BoxLayoutStyles{
+			Padding: 4,
+			Gap:     4,
+			SizeHint: eeui.SizeHint{
+				MaxHeight: 48,
+			},
+		},
+		btnConfigure, btnRotate, btnExplicitIO,
+	)
+
+	researchInfo := func() []string {
+		r, ok := ui.universe.Research()
+		if !ok {
+			return []string{"Research: disabled"}
+		}
+		return r.Info()
+	}
+	btnTechTree := eeui.NewButton("Tech tree", evts)
+	btnTechTree.OnClick(func() {
+		infoBox.ChangeTextFunc(researchInfo)
+	})
+	btnNextResearch := eeui.NewButton("Next research", evts)
+	btnNextResearch.OnClick(func() {
+		if r, ok := ui.universe.Research(); ok {
+			if tech, ok := r.NextAvailable(); ok {
+				r.SetCurrent(tech.ID)
+			}
+		}
+		infoBox.ChangeTextFunc(researchInfo)
+	})
+	researchLayout := eeui.NewHBoxLayout(
+		eeui.BoxLayoutStyles{
+			Padding: 4,
+			Gap:     4,
+			SizeHint: eeui.SizeHint{
+				MaxHeight: 48,
+			},
+		},
+		btnTechTree, btnNextResearch,
+	)
+
 	layout := eeui.NewVBoxLayout(
 		eeui.BoxLayoutStyles{
 			Padding: 4,
@@ -225,12 +315,17 @@ func New(uni *minifac.Universe) *UI {
 		assLayout,
 		finLayout,
 		miscLayout,
+		configLayout,
+		researchLayout,
+		scenarioBox,
+		sparkline,
 		infoBox,
 	)
 
 	font := mustLoadFont("fonts/inter/Inter-Medium.ttf")
 	menu := eeui.NewForm(layout, evts, font)
 	ui.menu = menu
+	ui.updatePalette()
 
 	ui.eventHandler.OnMouseRightClicked(func(p image.Point) {
 		x, y := p.X/int(ui.scaleX), p.Y/int(ui.scaleY)
@@ -245,20 +340,62 @@ func New(uni *minifac.Universe) *UI {
 		exobj, ok := ui.universe.ObjectAt(pos)
 		if !ok {
 			// add new object
-			obj, err := CreateObject(ui.selectedItem, ui.selectedResource)
+			obj, err := CreateObject(ui.selectedItem, ui.selectedResource, ui.selectedDir, pos)
 			if err != nil {
 				minifac.Log("ERROR: create-object: %v", err)
 				return
 			}
-			ui.universe.AddObject(obj, pos)
+			if err := ui.universe.AddObject(obj, pos); err != nil {
+				infoBox.ChangeTextFunc(func() []string {
+					return []string{
+						fmt.Sprintf("Terrain: %s", ui.universe.TerrainAt(pos)),
+						fmt.Sprintf("Cannot place: %v", err),
+					}
+				})
+			}
 		} else {
-			infoBox.ChangeTextFunc(exobj.Value.Info)
+			ui.selectedObject = exobj
+			_, isProducer := exobj.Value.(minifac.Producer)
+			infoBox.ChangeTextFunc(func() []string {
+				info := exobj.Value.Info()
+				if n, ok := ui.universe.PowerNetworkAt(pos); ok {
+					info = append(info, n.Info()...)
+				}
+				if stats, ok := ui.universe.Stats(exobj.Value); ok {
+					info = append(info, statsInfo(stats)...)
+				}
+				return info
+			})
+			sparkline.ChangeDataFunc(func() []float64 {
+				stats, ok := ui.universe.Stats(exobj.Value)
+				if !ok {
+					return []float64{}
+				}
+				var values []float64
+				for _, c := range stats.Samples() {
+					if isProducer {
+						values = append(values, float64(c.Produced))
+					} else {
+						values = append(values, float64(c.Consumed))
+					}
+				}
+				return values
+			})
 		}
 	})
 
 	return ui
 }
 
+func statsInfo(stats *minifac.ObjectStats) []string {
+	return []string{
+		fmt.Sprintf("Produced : %d", stats.Total.Produced),
+		fmt.Sprintf("Consumed : %d", stats.Total.Consumed),
+		fmt.Sprintf("Producing: %.1f%%", 100*stats.Utilization()),
+		fmt.Sprintf("Blocked  : %.1f%%", 100*stats.Blocked()),
+	}
+}
+
 type UI struct {
 	dx, dy           int
 	scaleX, scaleY   float64
@@ -271,6 +408,53 @@ type UI struct {
 	menu             *eeui.Form
 	selectedItem     ImageType
 	selectedResource minifac.Resource
+	selectedObject   *grid.Object[minifac.Object]
+	selectedDir      grid.Direction
+	scenario         *minifac.ScenarioRun
+	palette          []paletteButton
+	researched       int // number of completed technologies already reported
+}
+
+// paletteButton is a button of the palette together with the item it selects.
+type paletteButton struct {
+	button   *eeui.ImageButton
+	item     ImageType
+	resource minifac.Resource
+}
+
+// reportResearch logs the technologies completed since the last call.
+func (ui *UI) reportResearch() {
+	r, ok := ui.universe.Research()
+	if !ok {
+		return
+	}
+	completed := r.Completed()
+	for _, id := range completed[minifac.Min(ui.researched, len(completed)):] {
+		minifac.Log("research: %s completed", id)
+	}
+	ui.researched = len(completed)
+}
+
+// updatePalette shows the buttons of all researched items and hides the
+// others.
+func (ui *UI) updatePalette() {
+	var changed bool
+	for _, pb := range ui.palette {
+		unlocked := itemUnlocked(ui.universe, pb.item, pb.resource)
+		if pb.button.Visible() != unlocked {
+			pb.button.SetVisible(unlocked)
+			changed = true
+		}
+	}
+	if changed {
+		ui.menu.Relayout()
+	}
+}
+
+// SetScenario makes the UI tick the universe through the run and show its
+// progress. The run must be on the universe of the UI.
+func (ui *UI) SetScenario(run *minifac.ScenarioRun) {
+	ui.scenario = run
 }
 
 func (ui *UI) createBackground() {
@@ -291,7 +475,13 @@ func (ui *UI) Update() error {
 	ui.eventHandler.Update()
 	select {
 	case <-ui.ticker.C:
-		ui.universe.Tick()
+		if ui.scenario != nil {
+			ui.scenario.Tick()
+		} else {
+			ui.universe.Tick()
+		}
+		ui.reportResearch()
+		ui.updatePalette()
 	default:
 	}
 	return nil
@@ -314,6 +504,18 @@ func (ui *UI) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight
 	return outsideWidth, outsideHeight
 }
 
+// imageOptions scales and translates a positioned image to its rectangle.
+func (ui *UI) imageOptions(pimg *PositionedImage) *ebiten.DrawImageOptions {
+	bs := pimg.Image.Bounds()
+	r := pimg.Rectangle
+	scaleX, scaleY := ui.scaleX*float64(r.DX)/float64(bs.Dx()), ui.scaleY*float64(r.DY)/float64(bs.Dy())
+
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(scaleX, scaleY)
+	opts.GeoM.Translate(ui.scaleX*float64(r.X), ui.scaleY*float64(r.Y))
+	return opts
+}
+
 func (ui *UI) Draw(screen *ebiten.Image) {
 	if ui.backgroundImg == nil {
 		ui.createBackground()
@@ -321,15 +523,19 @@ func (ui *UI) Draw(screen *ebiten.Image) {
 	screen.DrawImage(ui.backgroundImg, &ebiten.DrawImageOptions{})
 
 	ebitenutil.DebugPrint(screen, fmt.Sprintf("%.2f", ebiten.ActualTPS()))
+	for _, pimg := range ui.imageHandler.TerrainImages() {
+		screen.DrawImage(pimg.Image, ui.imageOptions(pimg))
+	}
+	for _, pimg := range ui.imageHandler.DepositImages() {
+		opts := ui.imageOptions(pimg)
+		opts.ColorScale.ScaleAlpha(0.4)
+		screen.DrawImage(pimg.Image, opts)
+	}
 	pimgs := ui.imageHandler.Images()
 	for _, pimg := range pimgs {
-		bs := pimg.Image.Bounds()
-		scaleX, scaleY := ui.scaleX/float64(bs.Dx()), ui.scaleY/float64(bs.Dy())
-
-		opts := &ebiten.DrawImageOptions{}
-		opts.GeoM.Scale(scaleX, scaleY)
-		opts.GeoM.Translate(ui.scaleX*float64(pimg.Position.X), ui.scaleY*float64(pimg.Position.Y))
-		screen.DrawImage(pimg.Image, opts)
+		screen.DrawImage(pimg.Image, ui.imageOptions(pimg))
 	}
+	ui.drawTunnelLinks(screen)
+	ui.drawStatusBadges(screen)
 	ui.menu.Draw(screen)
 }
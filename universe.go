@@ -9,7 +9,7 @@ type Producer interface {
 	Produce() (Resource, bool)
 	Resource() Resource
 	CanProduce() bool
-	ProduceAtPositions(base grid.Position) []grid.Position
+	ProduceAtPositions(r grid.Rectangle) []grid.Position
 	Name() string
 }
 
@@ -17,10 +17,32 @@ type Consumer interface {
 	ConsumeFrom(Resource, grid.Direction)
 	CanConsumeFrom(Resource, grid.Direction) bool
 	CanConsumeAny() bool
-	ConsumeAtPositions(base grid.Position) []grid.Position
+	ConsumeAtPositions(r grid.Rectangle) []grid.Position
 	Name() string
 }
 
+// Distributor is implemented by producers, which need to know where their
+// items went to.
+type Distributor interface {
+	// ProducedTo is called when an item has been handed over in direction dir
+	ProducedTo(dir grid.Direction)
+}
+
+// MultiProducer is implemented by producers, which offer several resources
+// at once. A resource, which no neighbour takes, does not hold up the others.
+type MultiProducer interface {
+	// Offers returns the resources offered, in order of preference
+	Offers() []Resource
+	// ProduceResource hands out one item of res
+	ProduceResource(res Resource) bool
+}
+
+// machine is implemented by objects, which exchange items only with
+// inserters, if the universe uses explicit I/O.
+type machine interface {
+	isMachine()
+}
+
 type ProducerConsumer interface {
 	Producer
 	Consumer
@@ -33,15 +55,78 @@ type Object interface {
 	Info() []string
 }
 
+// NewUniverse creates an empty universe of the given size. Machines exchange
+// items only with inserters, so a producer next to a conveyor moves nothing
+// unless SetExplicitIO(false) is called.
 func NewUniverse(size grid.Size) *Universe {
 	u := &Universe{
-		grid: grid.New[Object](size),
+		grid:       grid.New[Object](size),
+		terrain:    grid.NewLayer[Terrain](size),
+		deposits:   grid.NewLayer[Deposit](size),
+		stats:      map[Object]*ObjectStats{},
+		delivered:  map[Resource]int{},
+		preset:     map[Object]bool{},
+		powerDirty: true,
+		explicitIO: true,
 	}
 	return u
 }
 
 type Universe struct {
-	grid *grid.Grid[Object]
+	grid     *grid.Grid[Object]
+	terrain  *grid.Layer[Terrain]
+	deposits *grid.Layer[Deposit]
+	tick     int
+	stats    map[Object]*ObjectStats
+	// items consumed by finalizers per resource
+	delivered map[Resource]int
+
+	// power networks, rebuilt when objects are added or deleted
+	networks   []*PowerNetwork
+	networkOf  map[Object]*PowerNetwork
+	powerDirty bool
+
+	explicitIO bool
+
+	// nil, if research is disabled
+	research *Research
+	// objects placed before research was enabled, which need no unlock
+	preset map[Object]bool
+}
+
+// SetExplicitIO sets whether machines exchange items only with inserters,
+// which is the default. Otherwise they push their output to and take their
+// input from any neighbour.
+func (u *Universe) SetExplicitIO(explicit bool) {
+	u.explicitIO = explicit
+}
+
+func (u *Universe) ExplicitIO() bool {
+	return u.explicitIO
+}
+
+// canExchange reports whether items may move directly from prod to con.
+// Chests never hand items to each other, which would pass them back and
+// forth.
+func (u *Universe) canExchange(prod Producer, con Consumer) bool {
+	_, prodIsChest := prod.(*Chest)
+	_, conIsChest := con.(*Chest)
+	if prodIsChest && conIsChest {
+		return false
+	}
+	if !u.explicitIO {
+		return true
+	}
+	_, prodIsMachine := prod.(machine)
+	_, conIsMachine := con.(machine)
+	_, prodIsInserter := prod.(*Inserter)
+	_, conIsInserter := con.(*Inserter)
+	return (!prodIsMachine || conIsInserter) && (!conIsMachine || prodIsInserter)
+}
+
+// Ticks returns the number of ticks the universe has advanced.
+func (u *Universe) Ticks() int {
+	return u.tick
 }
 
 func (u *Universe) Size() grid.Size {
@@ -53,12 +138,36 @@ func (u *Universe) ContainsPosition(p grid.Position) bool {
 }
 
 func (u *Universe) AddObject(o Object, at grid.Position) error {
+	err := u.checkPlacement(o, grid.R(at, o.Size()), false)
+	if err != nil {
+		return err
+	}
+	return u.place(o, at)
+}
+
+// place adds o without checking the placement rules.
+func (u *Universe) place(o Object, at grid.Position) error {
 	r := grid.R(at, o.Size())
-	return u.grid.Add(o, r)
+	err := u.grid.Add(o, r)
+	if err != nil {
+		return err
+	}
+	u.powerDirty = true
+	u.linkTunnel(u.grid.ObjectAt(at))
+	if m, ok := o.(*Miner); ok {
+		m.deposits, m.area = u.deposits, r
+	}
+	return nil
 }
 
 func (u *Universe) DeleteAt(p grid.Position) {
+	if obj := u.grid.ObjectAt(p); obj != nil {
+		unlinkTunnel(obj.Value)
+		delete(u.stats, obj.Value)
+		delete(u.preset, obj.Value)
+	}
 	u.grid.DeleteAt(p)
+	u.powerDirty = true
 }
 
 func (u *Universe) AllObjects() []*grid.Object[Object] {
@@ -73,45 +182,166 @@ func (u *Universe) ObjectAt(p grid.Position) (*grid.Object[Object], bool) {
 	return o, true
 }
 
+// Tick advances the universe by one tick.
+//
+// The power of all networks is balanced and the labs hand their items over
+// to the research first, then all objects are ticked. Afterwards transport
+// runs in two phases: the intended moves are collected against the state at
+// the start of the transport phase, then they are committed downstream
+// first. Items which arrive at an object during a tick can therefore not be
+// passed on in the same tick, while the room an object frees by passing on
+// an item can be taken in the same tick. So the throughput is independent of
+// direction and placement order, and a full line moves every tick.
 func (u *Universe) Tick() {
-	var prods []*grid.Object[Producer]
-	var cons []*grid.Object[Consumer]
-
-	findConsumerForPositions := func(fromPos grid.Position, poss []grid.Position, res Resource) (*grid.Object[Consumer], grid.Position, bool) {
-		for _, conObj := range cons {
-			for _, pos := range poss {
-				fromDir := grid.DirectionFrom(conObj.Position, fromPos)
-				if !conObj.Value.CanConsumeFrom(res, fromDir) {
-					continue
-				}
-				if slices.Contains(conObj.Value.ConsumeAtPositions(conObj.Position), pos) {
-					return conObj, pos, true
+	u.tick++
+	objs := u.grid.Objects()
+	u.updatePower(objs)
+	u.updateResearch(objs)
+	for _, obj := range objs {
+		obj.Value.Tick()
+	}
+	moves := u.collectMoves(objs)
+	u.commitMoves(moves)
+	u.recordTick(objs)
+}
+
+// target is a consumer which may take an item, together with the direction
+// the item comes from.
+type target struct {
+	object   Object
+	consumer Consumer
+	fromDir  grid.Direction
+}
+
+// move is an intended transport of one item from a producer to the first of
+// its neighbours, which is able to take it at commit time.
+type move struct {
+	object    Object
+	producer  Producer
+	rect      grid.Rectangle
+	resources []Resource // in order of preference
+	positions []grid.Position
+}
+
+func (u *Universe) collectMoves(objs []*grid.Object[Object]) []move {
+	var moves []move
+	for _, obj := range objs {
+		prod, ok := obj.Value.(Producer)
+		if !ok || !prod.CanProduce() {
+			continue
+		}
+		ress := []Resource{prod.Resource()}
+		if mp, ok := prod.(MultiProducer); ok {
+			ress = mp.Offers()
+		}
+		moves = append(moves, move{
+			object:    obj.Value,
+			producer:  prod,
+			rect:      obj.Rectangle,
+			resources: ress,
+			positions: prod.ProduceAtPositions(obj.Rectangle),
+		})
+	}
+	return moves
+}
+
+// targetAt looks up the consumer at pos, which is able to take res from a
+// producer occupying from.
+func (u *Universe) targetAt(pos grid.Position, from grid.Rectangle, res Resource) (target, bool) {
+	conObj := u.grid.ObjectAt(pos)
+	if conObj == nil {
+		return target{}, false
+	}
+	con, ok := conObj.Value.(Consumer)
+	if !ok || !con.CanConsumeAny() {
+		return target{}, false
+	}
+	fromDir := grid.DirectionFromRectangle(pos, from)
+	if !con.CanConsumeFrom(res, fromDir) {
+		return target{}, false
+	}
+	if !slices.Contains(con.ConsumeAtPositions(conObj.Rectangle), pos) {
+		return target{}, false
+	}
+	return target{object: conObj.Value, consumer: con, fromDir: fromDir}, true
+}
+
+// downstreamFirst orders the moves such that a move comes before the moves
+// delivering to its producer. Moves in a cycle keep their collected order
+// otherwise.
+func (u *Universe) downstreamFirst(moves []move) []move {
+	byObject := map[Object]int{}
+	for i, m := range moves {
+		byObject[m.object] = i
+	}
+	visited := make([]bool, len(moves))
+	ordered := make([]move, 0, len(moves))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		for _, pos := range moves[i].positions {
+			if o := u.grid.ObjectAt(pos); o != nil {
+				if j, ok := byObject[o.Value]; ok {
+					visit(j)
 				}
 			}
 		}
-		return nil, grid.Position{}, false
+		ordered = append(ordered, moves[i])
 	}
+	for i := range moves {
+		visit(i)
+	}
+	return ordered
+}
 
-	for _, obj := range u.grid.Objects() {
-		obj.Value.Tick()
-		if prod, ok := obj.Value.(Producer); ok && prod.CanProduce() {
-			prods = append(prods, &grid.Object[Producer]{Value: prod, Rectangle: obj.Rectangle})
-		}
-		if con, ok := obj.Value.(Consumer); ok && con.CanConsumeAny() {
-			cons = append(cons, &grid.Object[Consumer]{Value: con, Rectangle: obj.Rectangle})
+// commitMoves delivers the collected moves downstream first. Each move hands
+// over the first of its resources, which a neighbour is able to take at that
+// time, to the first such neighbour. The producer gives away its item right
+// after the delivery, so the room it frees is available to the moves
+// committed later. A resource which is no longer offered is skipped.
+func (u *Universe) commitMoves(moves []move) {
+	for _, m := range u.downstreamFirst(moves) {
+		for _, res := range m.resources {
+			if u.commitMove(m, res) {
+				break
+			}
 		}
 	}
-	// transport
-	for _, prodObj := range prods {
-		prodPoss := prodObj.Value.ProduceAtPositions(prodObj.Position)
-		res := prodObj.Value.Resource()
+}
 
-		conObj, pos, ok := findConsumerForPositions(prodObj.Position, prodPoss, res)
-		if !ok {
+// commitMove hands an item of res from the producer of m to the first
+// neighbour able to take it, and reports whether it did so.
+func (u *Universe) commitMove(m move, res Resource) bool {
+	mp, multi := m.producer.(MultiProducer)
+	switch {
+	case multi && !slices.Contains(mp.Offers(), res):
+		return false
+	case !multi && (!m.producer.CanProduce() || m.producer.Resource() != res):
+		return false
+	}
+	for _, pos := range m.positions {
+		t, ok := u.targetAt(pos, m.rect, res)
+		if !ok || !u.canExchange(m.producer, t.consumer) {
 			continue
 		}
-		fromDir := grid.DirectionFrom(conObj.Position, pos)
-		prodObj.Value.Produce()
-		conObj.Value.ConsumeFrom(res, fromDir)
+		t.consumer.ConsumeFrom(res, t.fromDir)
+		if d, ok := m.producer.(Distributor); ok {
+			d.ProducedTo(t.fromDir.Opposite())
+		}
+		if multi {
+			mp.ProduceResource(res)
+		} else {
+			m.producer.Produce()
+		}
+		u.record(m.object, Counters{Produced: 1})
+		u.record(t.object, Counters{Consumed: 1})
+		if _, ok := t.consumer.(*Finalizer); ok {
+			u.delivered[res]++
+		}
+		return true
 	}
+	return false
 }
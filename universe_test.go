@@ -0,0 +1,227 @@
+package minifac
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mazzegi/minifac/grid"
+)
+
+// setupLine creates a producer, a line of conveyors and a trashbin in row 1
+// of a universe. If mirrored, the line runs from east to west.
+func setupLine(length int, mirrored bool) *Universe {
+	size := grid.S(length+2, 3)
+	u := NewUniverse(size)
+	u.SetExplicitIO(false)
+	x := func(i int) int {
+		if mirrored {
+			return size.DX - 1 - i
+		}
+		return i
+	}
+	dir := grid.East
+	if mirrored {
+		dir = grid.West
+	}
+	u.AddObject(NewIncarnationProducer("prod", Coal, NewRate(1, 1), 2), grid.P(x(0), 1))
+	for i := 1; i <= length; i++ {
+		u.AddObject(NewConveyor(fmt.Sprintf("conv_%d", i), dir, 1), grid.P(x(i), 1))
+	}
+	u.AddObject(NewTrashbin("trash"), grid.P(x(length+1), 1))
+	return u
+}
+
+// consumed returns the number of items consumed by objects with the given name.
+func consumed(u *Universe, name string) int {
+	var n int
+	for _, obj := range u.AllObjects() {
+		if s, ok := u.Stats(obj.Value); ok && obj.Value.Name() == name {
+			n += s.Total.Consumed
+		}
+	}
+	return n
+}
+
+func TestTickMirrored(t *testing.T) {
+	tests := []struct {
+		length int
+		ticks  int
+	}{
+		{length: 1, ticks: 10},
+		{length: 5, ticks: 20},
+		{length: 10, ticks: 100},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test_#%02d", i), func(t *testing.T) {
+			u := setupLine(test.length, false)
+			mu := setupLine(test.length, true)
+			repeat(u.Tick, test.ticks)
+			repeat(mu.Tick, test.ticks)
+			if want, have := consumed(u, "trash"), consumed(mu, "trash"); want != have {
+				t.Fatalf("mirrored: want %d, have %d", want, have)
+			}
+		})
+	}
+}
+
+func TestTickOneTilePerTick(t *testing.T) {
+	length := 5
+	for _, mirrored := range []bool{false, true} {
+		u := setupLine(length, mirrored)
+		// the first item is produced and put on the first conveyor in tick 1,
+		// afterwards it moves one tile per tick
+		repeat(u.Tick, length)
+		if have := consumed(u, "trash"); have != 0 {
+			t.Fatalf("mirrored=%t: want %d, have %d", mirrored, 0, have)
+		}
+		u.Tick()
+		if have := consumed(u, "trash"); have != 1 {
+			t.Fatalf("mirrored=%t: want %d, have %d", mirrored, 1, have)
+		}
+	}
+}
+
+func TestTickFullLineThroughput(t *testing.T) {
+	// the first item arrives in tick 11, then one item per tick follows
+	for _, mirrored := range []bool{false, true} {
+		u := setupLine(10, mirrored)
+		repeat(u.Tick, 100)
+		if have := consumed(u, "trash"); have != 90 {
+			t.Fatalf("mirrored=%t: want %d, have %d", mirrored, 90, have)
+		}
+	}
+}
+
+func BenchmarkTick(b *testing.B) {
+	// 512 conveyor lines of length 510, each fed by a producer and emptied
+	// by a trashbin
+	size := grid.S(512, 512)
+	u := NewUniverse(size)
+	u.SetExplicitIO(false)
+	for y := 0; y < size.DY; y++ {
+		u.AddObject(NewIncarnationProducer("prod", Coal, NewRate(1, 1), 2), grid.P(0, y))
+		for x := 1; x < size.DX-1; x++ {
+			u.AddObject(NewConveyor("conv", grid.East, 1), grid.P(x, y))
+		}
+		u.AddObject(NewTrashbin("trash"), grid.P(size.DX-1, y))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u.Tick()
+	}
+}
+
+func TestTickMultiTileAssembler(t *testing.T) {
+	u := NewUniverse(grid.S(6, 6))
+	u.SetExplicitIO(false)
+	ass := NewSizedAssembler("ass", grid.S(2, 2), ReceiptSteel(), 5, 5)
+	u.AddObject(ass, grid.P(2, 2))
+	// inputs at two different cells of the assembler
+	u.AddObject(NewIncarnationProducer("prod_coal", Coal, NewRate(1, 1), 2), grid.P(1, 2))
+	u.AddObject(NewIncarnationProducer("prod_iron", Iron, NewRate(1, 1), 2), grid.P(3, 4))
+	u.AddObject(NewTrashbin("trash"), grid.P(4, 3))
+
+	if n := len(u.AllObjects()); n != 4 {
+		t.Fatalf("objects: want %d, have %d", 4, n)
+	}
+	repeat(u.Tick, 20)
+	if have := consumed(u, "trash"); have == 0 {
+		t.Fatalf("trash: want > 0, have %d", have)
+	}
+	u.DeleteAt(grid.P(3, 3))
+	for _, p := range grid.R(grid.P(2, 2), grid.S(2, 2)).Positions() {
+		if _, ok := u.ObjectAt(p); ok {
+			t.Fatalf("object-at %s: want none", p)
+		}
+	}
+}
+
+func TestStatsSamples(t *testing.T) {
+	u := setupLine(3, false)
+	ticks := (StatsSamples + 5) * StatsWindow
+	repeat(u.Tick, ticks)
+
+	prod, _ := u.ObjectAt(grid.P(0, 1))
+	s, ok := u.Stats(prod.Value)
+	if !ok {
+		t.Fatalf("stats: want prod, have none")
+	}
+	if s.Ticks != ticks {
+		t.Fatalf("ticks: want %d, have %d", ticks, s.Ticks)
+	}
+	samples := s.Samples()
+	if len(samples) != StatsSamples {
+		t.Fatalf("samples: want %d, have %d", StatsSamples, len(samples))
+	}
+	var sum int
+	for _, c := range samples {
+		sum += c.Produced
+	}
+	// the line is saturated, so all windows count the same
+	if want := samples[0].Produced * StatsSamples; sum != want {
+		t.Fatalf("sum: want %d, have %d", want, sum)
+	}
+	if want := consumed(u, "trash"); s.Total.Produced-want > 4 {
+		t.Fatalf("produced: want about %d, have %d", want, s.Total.Produced)
+	}
+}
+
+func TestStatsPerObject(t *testing.T) {
+	u := NewUniverse(grid.S(2, 2))
+	u.SetExplicitIO(false)
+	prod := NewIncarnationProducer("prod", Coal, NewRate(1, 1), 2)
+	trash1, trash2 := NewTrashbin("trash"), NewTrashbin("trash")
+	u.AddObject(prod, grid.P(0, 0))
+	u.AddObject(trash1, grid.P(1, 0))
+	u.AddObject(trash2, grid.P(1, 1))
+	repeat(u.Tick, 10)
+
+	s1, _ := u.Stats(trash1)
+	s2, _ := u.Stats(trash2)
+	if s1 == s2 {
+		t.Fatalf("objects sharing a name: want separate stats")
+	}
+	ps, _ := u.Stats(prod)
+	if have := s1.Total.Consumed + s2.Total.Consumed; have != ps.Total.Produced {
+		t.Fatalf("consumed: want %d, have %d", ps.Total.Produced, have)
+	}
+	if have := ps.Utilization(); have != 1 {
+		t.Fatalf("utilization of prod: want %g, have %g", 1.0, have)
+	}
+	u.DeleteAt(grid.P(1, 0))
+	if _, ok := u.Stats(trash1); ok {
+		t.Fatalf("stats of deleted object: want none")
+	}
+}
+
+func TestConveyorTravelTime(t *testing.T) {
+	c := NewConveyorWithSpeed("conv", grid.East, 2, 3)
+	c.ConsumeFrom(Coal, grid.West)
+	repeat(c.Tick, 2)
+	if c.CanProduce() {
+		t.Fatalf("item left after %d ticks: want %d", 2, 3)
+	}
+	c.Tick()
+	if res, ok := c.Produce(); !ok || res != Coal {
+		t.Fatalf("produce: want %s, have %s", Coal, res)
+	}
+}
+
+func TestConveyorTiers(t *testing.T) {
+	throughput := map[string]int{}
+	for _, tier := range ConveyorTiers {
+		u := NewUniverse(grid.S(7, 1))
+		u.SetExplicitIO(false)
+		u.AddObject(NewIncarnationProducer("prod", Coal, NewRate(1, 1), 2), grid.P(0, 0))
+		for i := 1; i <= 5; i++ {
+			u.AddObject(NewConveyorOfTier(fmt.Sprintf("conv_%d", i), grid.East, tier), grid.P(i, 0))
+		}
+		u.AddObject(NewTrashbin("trash"), grid.P(6, 0))
+		repeat(u.Tick, 200)
+		throughput[tier.Name] = consumed(u, "trash")
+	}
+	if throughput["slow"] >= throughput["normal"] || throughput["normal"] >= throughput["fast"] {
+		t.Fatalf("throughput not ordered by tier: %v", throughput)
+	}
+}